@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/andrealungh1/HeaderSec/config"
+	"github.com/andrealungh1/HeaderSec/fingerprint"
 	"github.com/andrealungh1/HeaderSec/output"
+	"github.com/andrealungh1/HeaderSec/preload"
 	"github.com/andrealungh1/HeaderSec/scanner"
 	"github.com/andrealungh1/HeaderSec/transport"
+	"github.com/andrealungh1/HeaderSec/vulndb"
 )
 
 func main() {
@@ -21,28 +25,48 @@ func main() {
 
 		fmt.Fprintln(flag.CommandLine.Output(), "Target selection:")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -url string\n\tURL to check")
-		fmt.Fprintln(flag.CommandLine.Output(), "  -url-file string\n\tText file with one URL per line")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -url-file string\n\tText file with one URL per line, optionally followed by the tag 'logout'")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -port int\n\tOverride port")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -proxy string\n\tProxy URL, e.g. http://127.0.0.1:8080")
-		fmt.Fprintln(flag.CommandLine.Output(), "  -insecure\n\tSkip TLS certificate verification\n")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -insecure\n\tSkip TLS certificate verification")
+		fmt.Fprintln(flag.CommandLine.Output())
 
 		fmt.Fprintln(flag.CommandLine.Output(), "Request customization:")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -method string\n\tHTTP method to use (default \"HEAD\")")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -cookie string\n\tCookie in the format k=v")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -user-agent string\n\tCustom User-Agent string")
-		fmt.Fprintln(flag.CommandLine.Output(), "  -H string\n\tExtra headers, format: 'K: V;K2: V2'\n")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -H string\n\tExtra headers, format: 'K: V;K2: V2'")
+		fmt.Fprintln(flag.CommandLine.Output())
 
 		fmt.Fprintln(flag.CommandLine.Output(), "Redirect and timeout:")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -follow-redirects\n\tFollow HTTP redirects (default true)")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -max-redirects int\n\tMaximum number of redirects to follow (default 10)")
-		fmt.Fprintln(flag.CommandLine.Output(), "  -timeout int\n\tRequest timeout in seconds (default 10)\n")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -timeout int\n\tRequest timeout in seconds (default 10)")
+		fmt.Fprintln(flag.CommandLine.Output())
 
 		fmt.Fprintln(flag.CommandLine.Output(), "Scan behavior:")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -concurrency int\n\tNumber of concurrent workers (default 20)")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -rec\n\tInclude only recommended headers check")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -leak\n\tInclude only info-leaking headers check")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -depr\n\tInclude only deprecated headers check")
-		fmt.Fprintln(flag.CommandLine.Output(), "  -no-raccomanded\n\tPrint only PRESENT or MISSING without printing the recommended values\n")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -cookies\n\tInclude only Set-Cookie attributes check")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -cors\n\tInclude only CORS headers check")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -reporting\n\tInclude only Reporting-Endpoints, Report-To and NEL check")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -edge\n\tInclude only CDN and WAF detection")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -altsvc\n\tInclude only Alt-Svc and HTTP/3 advertisement check")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -no-raccomanded\n\tPrint only PRESENT or MISSING without printing the recommended values")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -cors-probe\n\tActively probe for CORS origin reflection with crafted Origin headers")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -sniff-body\n\tSend an extra GET to compare Content-Type with the body when -method is not GET")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -preload\n\tCheck HSTS preload eligibility of each target's domain")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -preload-list string\n\tChromium HSTS preload list JSON file (default: bundled sample, which can only confirm well-known entries)")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -vulndb string\n\tNVD JSON feed (1.1 or API 2.0, optionally gzipped) used to correlate disclosed versions with known CVEs")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -signatures string\n\tTechnology signature JSON file (default: bundled signatures)")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -secret-detectors string\n\tComma-separated secret detectors to run, or 'none' (default: all of "+strings.Join(output.SecretDetectorNames(), ", ")+")")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -internal-domains string\n\tComma-separated domain suffixes reported as internal hostnames\n\t(default \""+strings.Join(output.DefaultInternalDomains, ",")+"\")")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -edge-signatures string\n\tExtra CDN/WAF signature JSON file, added to the bundled providers")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -logout\n\tTreat every target as a logout endpoint")
+		fmt.Fprintln(flag.CommandLine.Output(), "  -logout-patterns string\n\tComma-separated regexes matched against each URL path segment to detect logout endpoints\n\t(default \""+config.DefaultLogoutPatterns+"\")")
+		fmt.Fprintln(flag.CommandLine.Output())
 
 		fmt.Fprintln(flag.CommandLine.Output(), "Output:")
 		fmt.Fprintln(flag.CommandLine.Output(), "  -json string\n\tOutput JSON file ('-' for stdout)")
@@ -57,6 +81,11 @@ func main() {
 		return
 	}
 
+	if err != nil {
+		output.LogError("%v", err)
+		os.Exit(1)
+	}
+
 	if cfg.NoRaccomanded {
 		output.ShowRecommendedDetails = false
 	}
@@ -65,10 +94,42 @@ func main() {
 		output.DisableColors()
 	}
 
+	output.SetInternalDomains(cfg.InternalDomains)
+
+	if err := output.SelectSecretDetectors(cfg.SecretDetectors); err != nil {
+		output.LogError("%v", err)
+		os.Exit(1)
+	}
+
 	if !cfg.NoBanner {
 		config.PrintBanner()
 	}
 
+	var preloadList *preload.List
+	if cfg.Preload {
+		preloadList, err = preload.Load(cfg.PreloadList)
+		if err != nil {
+			output.LogError("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	var vulnDB *vulndb.DB
+	if cfg.VulnDB != "" {
+		vulnDB, err = vulndb.Load(cfg.VulnDB)
+		if err != nil {
+			output.LogError("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	fingerprints, err := fingerprint.Load(cfg.Signatures)
+	if err != nil {
+		output.LogError("%v", err)
+		os.Exit(1)
+	}
+
+	edgeSignatures, err := fingerprint.LoadEdge(cfg.EdgeSignatures)
 	if err != nil {
 		output.LogError("%v", err)
 		os.Exit(1)
@@ -92,10 +153,27 @@ func main() {
 		UserAgent:    cfg.UserAgent,
 		ExtraHeaders: cfg.ExtraHeaders,
 		PortOverride: cfg.PortOverride,
-		IncludeRec:   cfg.IncludeRec,
-		IncludeLeak:  cfg.IncludeLeak,
-		IncludeDepr:  cfg.IncludeDepr,
-		OutputJSON:   cfg.OutputJSON,
+		Checks: output.Checks{
+			Rec:       cfg.IncludeRec,
+			Leak:      cfg.IncludeLeak,
+			Depr:      cfg.IncludeDepr,
+			Cookies:   cfg.IncludeCookies,
+			CORS:      cfg.IncludeCORS,
+			Reporting: cfg.IncludeReporting,
+			Edge:      cfg.IncludeEdge,
+			AltSvc:    cfg.IncludeAltSvc,
+		},
+		OutputJSON: cfg.OutputJSON,
+		Insecure:   cfg.Insecure,
+		Preload:    preloadList,
+		CORSProbe:  cfg.CORSProbe,
+		SniffBody:  cfg.SniffBody,
+
+		LogoutPatterns: cfg.LogoutPatterns,
+		LogoutTargets:  cfg.LogoutTargets,
+		VulnDB:         vulnDB,
+		Fingerprints:   fingerprints,
+		EdgeSignatures: edgeSignatures,
 	}, cfg.Targets, cfg.Workers)
 
 	fmt.Println(output.Green + "Done." + output.Reset)
@@ -2,9 +2,16 @@ package config
 
 import (
 	"flag"
+	"regexp"
+	"strings"
 	"time"
+
+	"github.com/andrealungh1/HeaderSec/output"
 )
 
+// DefaultLogoutPatterns identifies logout endpoints by their URL path.
+const DefaultLogoutPatterns = "log[-_]?out,sign[-_]?out,log[-_]?off,sign[-_]?off,end[-_]?session"
+
 type App struct {
 	Targets        []string
 	Method         string
@@ -17,7 +24,7 @@ type App struct {
 	MaxRedirects   int
 	Workers        int
 
-	IncludeRec, IncludeLeak, IncludeDepr bool
+	IncludeRec, IncludeLeak, IncludeDepr, IncludeCookies, IncludeCORS, IncludeReporting, IncludeEdge, IncludeAltSvc bool
 
 	OutputJSON    string
 	Insecure      bool
@@ -25,6 +32,22 @@ type App struct {
 	NoBanner      bool
 	NoColor       bool
 	NoRaccomanded bool
+	Preload       bool
+	PreloadList   string
+	CORSProbe     bool
+	SniffBody     bool
+
+	// LogoutPatterns and LogoutTargets identify logout endpoints, where
+	// Clear-Site-Data is expected.
+	LogoutPatterns []*regexp.Regexp
+	LogoutTargets  map[string]bool
+
+	VulnDB         string
+	Signatures     string
+	EdgeSignatures string
+
+	SecretDetectors []string
+	InternalDomains []string
 }
 
 func Parse() (*App, error) {
@@ -43,26 +66,52 @@ func Parse() (*App, error) {
 		recFlag   = flag.Bool("rec", false, "Include only recommended headers check")
 		leakFlag  = flag.Bool("leak", false, "Include only info-leaking headers check")
 		depFlag   = flag.Bool("depr", false, "Include only deprecated headers check")
+		cookFlag  = flag.Bool("cookies", false, "Include only Set-Cookie attributes check")
+		corsFlag  = flag.Bool("cors", false, "Include only CORS headers check")
+		repFlag   = flag.Bool("reporting", false, "Include only Reporting-Endpoints, Report-To and NEL check")
+		edgeFlag  = flag.Bool("edge", false, "Include only CDN and WAF detection")
+		altFlag   = flag.Bool("altsvc", false, "Include only Alt-Svc and HTTP/3 advertisement check")
 		jsonOut   = flag.String("json", "", "Output JSON file ('-' for stdout)")
 		insecure  = flag.Bool("insecure", false, "Skip TLS certificate verification")
 		proxyURL  = flag.String("proxy", "", "Proxy URL, e.g. http://127.0.0.1:8080")
 		noBanner  = flag.Bool("no-banner", false, "Don't print banner")
 		noColor   = flag.Bool("no-color", false, "Disable ANSI colours in output")
 		noRec     = flag.Bool("no-raccomanded", false, "Show only MISSING or PRESENT for recommended headers")
+		corsProbe = flag.Bool("cors-probe", false, "Actively probe for CORS origin reflection")
+		sniffBody = flag.Bool("sniff-body", false, "Send an extra GET to compare Content-Type with the body when -method is not GET")
+		preloadOn = flag.Bool("preload", false, "Check HSTS preload eligibility of each target's domain")
+		preloadDB = flag.String("preload-list", "", "Chromium HSTS preload list JSON file (default: bundled sample, which can only confirm well-known entries)")
+		logoutPat = flag.String("logout-patterns", DefaultLogoutPatterns, "Comma-separated regexes matched against each URL path segment to detect logout endpoints")
+		logoutAll = flag.Bool("logout", false, "Treat every target as a logout endpoint")
+		vulnDB    = flag.String("vulndb", "", "NVD JSON feed used to correlate disclosed versions with known CVEs")
+		sigFile   = flag.String("signatures", "", "Technology signature JSON file (default: bundled signatures)")
+		secrets   = flag.String("secret-detectors", "", "Comma-separated secret detectors to run, or 'none' (default: all)")
+		internal  = flag.String("internal-domains", strings.Join(output.DefaultInternalDomains, ","), "Comma-separated domain suffixes reported as internal hostnames")
+		edgeFile  = flag.String("edge-signatures", "", "Extra CDN/WAF signature JSON file, added to the bundled providers")
 	)
 
 	flag.Parse()
 
-	if !*recFlag && !*leakFlag && !*depFlag {
-		*recFlag, *leakFlag, *depFlag = true, true, true
+	if !*recFlag && !*leakFlag && !*depFlag && !*cookFlag && !*corsFlag && !*repFlag && !*edgeFlag && !*altFlag {
+		*recFlag, *leakFlag, *depFlag, *cookFlag, *corsFlag, *repFlag, *edgeFlag, *altFlag = true, true, true, true, true, true, true, true
 	}
 
-	targets, err := collectTargets(*urlStr, *urlFile)
+	targets, logoutTargets, err := collectTargets(*urlStr, *urlFile)
 	if err != nil {
 		return nil, err
 	}
 
 	targets = DedupeURLs(targets)
+	if *logoutAll {
+		for _, t := range targets {
+			logoutTargets[t] = true
+		}
+	}
+
+	logoutPatterns, err := parseLogoutPatterns(*logoutPat)
+	if err != nil {
+		return nil, err
+	}
 
 	return &App{
 		Targets:        targets,
@@ -76,9 +125,14 @@ func Parse() (*App, error) {
 		MaxRedirects:   *maxRed,
 		Workers:        *workers,
 
-		IncludeRec:  *recFlag,
-		IncludeLeak: *leakFlag,
-		IncludeDepr: *depFlag,
+		IncludeRec:       *recFlag,
+		IncludeLeak:      *leakFlag,
+		IncludeDepr:      *depFlag,
+		IncludeCookies:   *cookFlag,
+		IncludeCORS:      *corsFlag,
+		IncludeReporting: *repFlag,
+		IncludeEdge:      *edgeFlag,
+		IncludeAltSvc:    *altFlag,
 
 		OutputJSON:    *jsonOut,
 		Insecure:      *insecure,
@@ -86,5 +140,19 @@ func Parse() (*App, error) {
 		NoBanner:      *noBanner,
 		NoColor:       *noColor,
 		NoRaccomanded: *noRec,
+		Preload:       *preloadOn,
+		PreloadList:   *preloadDB,
+		CORSProbe:     *corsProbe,
+		SniffBody:     *sniffBody,
+
+		LogoutPatterns: logoutPatterns,
+		LogoutTargets:  logoutTargets,
+
+		VulnDB:         *vulnDB,
+		Signatures:     *sigFile,
+		EdgeSignatures: *edgeFile,
+
+		SecretDetectors: splitList(*secrets),
+		InternalDomains: splitList(*internal),
 	}, nil
 }
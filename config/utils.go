@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/andrealungh1/HeaderSec/output"
 	"os"
+	"regexp"
 	"strings"
 )
 
@@ -16,8 +17,12 @@ const Banner = "  _   _                _           ____            \n" +
 	"                                                  \n" +
 	"  	 By Andrea Lunghi v1.0.3\n"
 
-func collectTargets(single, file string) ([]string, error) {
+// collectTargets reads the targets from -url and -url-file. A line in the
+// URL file may be followed by whitespace-separated tags; the only tag
+// understood is "logout", which marks the target as a logout endpoint.
+func collectTargets(single, file string) ([]string, map[string]bool, error) {
 	var targets []string
+	logout := make(map[string]bool)
 
 	if single != "" {
 		targets = append(targets, single)
@@ -26,25 +31,64 @@ func collectTargets(single, file string) ([]string, error) {
 	if file != "" {
 		f, err := os.Open(file)
 		if err != nil {
-			return nil, fmt.Errorf("Opening URL file: %w", err)
+			return nil, nil, fmt.Errorf("Opening URL file: %w", err)
 		}
 		defer f.Close()
 
 		sc := bufio.NewScanner(f)
-		for sc.Scan() {
-			if u := strings.TrimSpace(sc.Text()); u != "" {
-				targets = append(targets, u)
+		for line := 1; sc.Scan(); line++ {
+			fields := strings.Fields(sc.Text())
+			if len(fields) == 0 {
+				continue
 			}
+			u := fields[0]
+			for _, tag := range fields[1:] {
+				if !strings.EqualFold(tag, "logout") {
+					return nil, nil, fmt.Errorf("URL file line %d: unknown tag %q", line, tag)
+				}
+				logout[u] = true
+			}
+			targets = append(targets, u)
 		}
 		if err := sc.Err(); err != nil {
-			return nil, fmt.Errorf("Reading URL file: %w", err)
+			return nil, nil, fmt.Errorf("Reading URL file: %w", err)
 		}
 	}
 
 	if len(targets) == 0 {
-		return nil, fmt.Errorf("You must specify either -url or -url-file")
+		return nil, nil, fmt.Errorf("You must specify either -url or -url-file")
+	}
+	return targets, logout, nil
+}
+
+// parseLogoutPatterns compiles the comma-separated -logout-patterns value.
+// Patterns are case-insensitive regular expressions anchored to a whole
+// segment of the URL path, so that log[-_]?out matches /auth/logout but not
+// /blog/logout-best-practices.
+func parseLogoutPatterns(raw string) ([]*regexp.Regexp, error) {
+	var out []*regexp.Regexp
+	for _, p := range strings.Split(raw, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		re, err := regexp.Compile("(?i)^(?:" + p + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("Invalid logout pattern %q: %w", p, err)
+		}
+		out = append(out, re)
 	}
-	return targets, nil
+	return out, nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(raw string) []string {
+	var out []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
 }
 
 func parseExtra(raw string) map[string]string {
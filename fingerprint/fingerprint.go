@@ -0,0 +1,263 @@
+// Package fingerprint identifies the technology stack behind a response
+// from header names, header values and cookie names, using a signature
+// database in the spirit of Wappalyzer.
+package fingerprint
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed technologies.json
+var bundled []byte
+
+//go:embed edge.json
+var bundledEdge []byte
+
+// Signature describes how to recognise one technology. Header and cookie
+// patterns are case-insensitive regular expressions; an empty pattern only
+// requires the header or cookie to be present. A pattern may end with
+// \;confidence:N to lower its weight (default 100). The first capture
+// group, when it matches, is taken as the version.
+type Signature struct {
+	Name     string            `json:"name"`
+	Category string            `json:"category"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Cookies  map[string]string `json:"cookies,omitempty"`
+	Implies  []string          `json:"implies,omitempty"`
+}
+
+type pattern struct {
+	re         *regexp.Regexp
+	confidence int
+}
+
+type compiled struct {
+	Signature
+	headers map[string]pattern
+	cookies map[string]pattern
+}
+
+type DB struct {
+	sigs []compiled
+}
+
+// Match is a detected technology.
+type Match struct {
+	Name       string
+	Category   string
+	Version    string
+	Confidence int
+	Evidence   []string
+}
+
+// Load reads a signature file. An empty path loads the bundled signatures.
+func Load(path string) (*DB, error) {
+	data := bundled
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("Reading signatures: %w", err)
+		}
+	}
+	return Parse(data)
+}
+
+// LoadEdge reads the bundled CDN and WAF providers, extended with the
+// signatures in the file at extra when it is not empty.
+func LoadEdge(extra string) (*DB, error) {
+	db, err := Parse(bundledEdge)
+	if err != nil || extra == "" {
+		return db, err
+	}
+	more, err := Load(extra)
+	if err != nil {
+		return nil, err
+	}
+	db.Merge(more)
+	return db, nil
+}
+
+// Parse decodes and compiles a signature file of the form
+// {"technologies": [Signature, ...]}.
+func Parse(data []byte) (*DB, error) {
+	var raw struct {
+		Technologies []Signature `json:"technologies"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("Parsing signatures: %w", err)
+	}
+	db := &DB{}
+	for _, s := range raw.Technologies {
+		if s.Name == "" {
+			return nil, fmt.Errorf("Parsing signatures: technology without a name")
+		}
+		c := compiled{Signature: s, headers: map[string]pattern{}, cookies: map[string]pattern{}}
+		for _, set := range []struct {
+			in  map[string]string
+			out map[string]pattern
+		}{{s.Headers, c.headers}, {s.Cookies, c.cookies}} {
+			for k, v := range set.in {
+				p, err := compile(v)
+				if err != nil {
+					return nil, fmt.Errorf("Parsing signatures: %s: %q: %w", s.Name, k, err)
+				}
+				set.out[k] = p
+			}
+		}
+		db.sigs = append(db.sigs, c)
+	}
+	return db, nil
+}
+
+func compile(raw string) (pattern, error) {
+	p := pattern{confidence: 100}
+	parts := strings.Split(raw, `\;`)
+	for _, opt := range parts[1:] {
+		if v, ok := strings.CutPrefix(opt, "confidence:"); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || n > 100 {
+				return p, fmt.Errorf("invalid confidence %q", v)
+			}
+			p.confidence = n
+		}
+	}
+	re, err := regexp.Compile("(?i)" + parts[0])
+	p.re = re
+	return p, err
+}
+
+// Merge appends the signatures of other, so that extra signature files can
+// extend the bundled ones.
+func (db *DB) Merge(other *DB) {
+	db.sigs = append(db.sigs, other.sigs...)
+}
+
+// Len returns the number of signatures.
+func (db *DB) Len() int {
+	return len(db.sigs)
+}
+
+// cookieNames returns the names of the cookies set by h.
+func cookieNames(h http.Header) map[string]string {
+	out := map[string]string{}
+	for _, sc := range h.Values("Set-Cookie") {
+		pair, _, _ := strings.Cut(sc, ";")
+		name, value, _ := strings.Cut(pair, "=")
+		out[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return out
+}
+
+// cookieMatch reports the cookie that name refers to. A trailing '*' in name
+// matches any suffix, as in BIGipServer*.
+func cookieMatch(cookies map[string]string, name string) (string, string, bool) {
+	if prefix, ok := strings.CutSuffix(name, "*"); ok {
+		keys := make([]string, 0, len(cookies))
+		for k := range cookies {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if strings.HasPrefix(k, prefix) {
+				return k, cookies[k], true
+			}
+		}
+		return "", "", false
+	}
+	v, ok := cookies[name]
+	return name, v, ok
+}
+
+// impliedWeight is the share, in percent, of a match's confidence passed on
+// to each technology it implies, so that every step of a chain is less
+// certain than the last.
+const impliedWeight = 80
+
+// Detect returns the technologies whose signatures match h, followed by the
+// technologies they imply.
+func (db *DB) Detect(h http.Header) []Match {
+	cookies := cookieNames(h)
+	found := map[string]*Match{}
+	var order []string
+	hit := func(s Signature, p pattern, groups []string, evidence string) {
+		m, ok := found[s.Name]
+		if !ok {
+			m = &Match{Name: s.Name, Category: s.Category}
+			found[s.Name] = m
+			order = append(order, s.Name)
+		}
+		m.Confidence = min(100, m.Confidence+p.confidence)
+		m.Evidence = append(m.Evidence, evidence)
+		for _, g := range groups[1:] {
+			if g != "" && m.Version == "" {
+				m.Version = g
+			}
+		}
+	}
+
+	for _, s := range db.sigs {
+		names := make([]string, 0, len(s.headers))
+		for name := range s.headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p := s.headers[name]
+			for _, v := range h.Values(name) {
+				if groups := p.re.FindStringSubmatch(v); groups != nil {
+					hit(s.Signature, p, groups, http.CanonicalHeaderKey(name)+": "+v)
+					break
+				}
+			}
+		}
+
+		names = names[:0]
+		for name := range s.cookies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p := s.cookies[name]
+			if cookie, v, ok := cookieMatch(cookies, name); ok {
+				if groups := p.re.FindStringSubmatch(v); groups != nil {
+					hit(s.Signature, p, groups, "cookie "+cookie)
+				}
+			}
+		}
+	}
+
+	categories := map[string]string{}
+	for _, s := range db.sigs {
+		categories[s.Name] = s.Category
+	}
+	for i := 0; i < len(order); i++ {
+		m := found[order[i]]
+		for _, s := range db.sigs {
+			if s.Name != m.Name {
+				continue
+			}
+			confidence := m.Confidence * impliedWeight / 100
+			for _, imp := range s.Implies {
+				if _, ok := found[imp]; ok {
+					continue
+				}
+				found[imp] = &Match{Name: imp, Category: categories[imp], Confidence: confidence, Evidence: []string{"implied by " + m.Name}}
+				order = append(order, imp)
+			}
+		}
+	}
+
+	out := make([]Match, 0, len(order))
+	for _, name := range order {
+		out = append(out, *found[name])
+	}
+	return out
+}
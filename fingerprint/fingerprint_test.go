@@ -0,0 +1,111 @@
+package fingerprint
+
+import (
+	"net/http"
+	"testing"
+)
+
+type want struct {
+	name, version string
+	confidence    int
+}
+
+func header(kv ...string) http.Header {
+	h := http.Header{}
+	for i := 0; i < len(kv); i += 2 {
+		h.Add(kv[i], kv[i+1])
+	}
+	return h
+}
+
+func check(t *testing.T, db *DB, h http.Header, wants []want, absent []string) {
+	t.Helper()
+	got := map[string]Match{}
+	for _, m := range db.Detect(h) {
+		got[m.Name] = m
+	}
+	for _, w := range wants {
+		m, ok := got[w.name]
+		if !ok {
+			t.Errorf("%v: %s not detected", h, w.name)
+			continue
+		}
+		if m.Version != w.version || m.Confidence != w.confidence {
+			t.Errorf("%v: %s = version %q confidence %d, want version %q confidence %d", h, w.name, m.Version, m.Confidence, w.version, w.confidence)
+		}
+		if len(m.Evidence) == 0 {
+			t.Errorf("%v: %s has no evidence", h, w.name)
+		}
+	}
+	for _, name := range absent {
+		if _, ok := got[name]; ok {
+			t.Errorf("%v: %s detected, want absent", h, name)
+		}
+	}
+}
+
+func TestDetectBundled(t *testing.T) {
+	db, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		h      http.Header
+		wants  []want
+		absent []string
+	}{
+		{header("Server", "nginx/1.18.0"), []want{{"nginx", "1.18.0", 100}}, []string{"OpenResty", "Apache HTTP Server"}},
+		{header("Server", "Apache/2.4.41 (Ubuntu)"), []want{{"Apache HTTP Server", "2.4.41", 100}}, []string{"Apache Tomcat"}},
+		{header("server", "Apache-Coyote/1.1"), []want{{"Apache Tomcat", "1.1", 100}, {"Java", "", 80}}, []string{"Apache HTTP Server"}},
+		{header("X-Powered-By", "PHP/8.1.2", "Set-Cookie", "PHPSESSID=abc; Path=/"), []want{{"PHP", "8.1.2", 100}}, nil},
+		{header("Set-Cookie", "BIGipServerpool_web=1677787402.36895.0000; path=/"), []want{{"F5 BIG-IP LTM", "", 100}}, nil},
+		{header("Set-Cookie", "TS01abcdef=0123; Path=/"), []want{{"F5 BIG-IP LTM", "", 50}}, nil},
+		{header("Set-Cookie", "BIGip=1"), nil, []string{"F5 BIG-IP LTM"}},
+		{header("Set-Cookie", "laravel_session=x", "Set-Cookie", "XSRF-TOKEN=y"), []want{{"Laravel", "", 100}, {"PHP", "", 80}}, nil},
+		// Chains lose confidence at every step.
+		{header("Via", "kong/2.8.1"), []want{{"Kong", "2.8.1", 100}, {"OpenResty", "", 80}, {"nginx", "", 64}, {"Lua", "", 64}}, nil},
+		{header("X-Powered-By", "W3 Total Cache/0.9.7"), []want{{"W3 Total Cache", "0.9.7", 100}, {"WordPress", "", 80}, {"PHP", "", 64}}, nil},
+		// A direct match is not replaced by an implication.
+		{header("Server", "openresty/1.21.4.1", "X-Powered-By", "PHP/8.2.0"), []want{{"OpenResty", "1.21.4.1", 100}, {"nginx", "", 80}}, nil},
+		{header("Server", "Kong", "Via", "kong/3.0"), []want{{"Kong", "3.0", 100}}, nil},
+		{header("Content-Type", "text/html"), nil, []string{"nginx", "PHP"}},
+	}
+	for _, tt := range tests {
+		check(t, db, tt.h, tt.wants, tt.absent)
+	}
+}
+
+func TestDetectEdge(t *testing.T) {
+	db, err := LoadEdge("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		h      http.Header
+		wants  []want
+		absent []string
+	}{
+		{header("Server", "cloudflare", "CF-Ray", "7d1a2b3c4d5e6f70-AMS"), []want{{"Cloudflare", "", 100}}, []string{"Akamai"}},
+		{header("Set-Cookie", "__cfruid=abc; path=/"), []want{{"Cloudflare", "", 50}}, nil},
+		{header("Via", "1.1 abcdef.cloudfront.net (CloudFront)", "X-Amz-Cf-Pop", "FRA56-C1"), []want{{"Amazon CloudFront", "", 100}}, nil},
+		{header("Set-Cookie", "TS01abcdef=0123; Path=/"), []want{{"F5 BIG-IP ASM", "", 75}}, nil},
+		{header("CF-Mitigated", "challenge"), []want{{"Cloudflare Bot Management", "", 100}}, []string{"Cloudflare"}},
+		{header("Server", "nginx"), nil, []string{"Cloudflare", "Akamai", "Amazon CloudFront"}},
+	}
+	for _, tt := range tests {
+		check(t, db, tt.h, tt.wants, tt.absent)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{`,
+		`{"technologies": [{"category": "cms"}]}`,
+		`{"technologies": [{"name": "x", "headers": {"Server": "("}}]}`,
+		`{"technologies": [{"name": "x", "headers": {"Server": "x\\;confidence:101"}}]}`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%s) succeeded, want an error", data)
+		}
+	}
+}
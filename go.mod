@@ -4,6 +4,9 @@ go 1.23.0
 
 toolchain go1.24.2
 
-require golang.org/x/term v0.32.0
+require (
+	golang.org/x/net v0.40.0
+	golang.org/x/term v0.32.0
+)
 
 require golang.org/x/sys v0.33.0 // indirect
@@ -0,0 +1,214 @@
+package output
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// AltService is one alternative advertised in Alt-Svc (RFC 7838). An empty
+// Host means the origin's host. MaxAge is in seconds.
+type AltService struct {
+	Protocol string `json:"protocol"`
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port"`
+	MaxAge   int64  `json:"max_age"`
+	Persist  bool   `json:"persist,omitempty"`
+}
+
+// AltSvcReport summarises the alternative services of a response and
+// whether HTTP/3 is among them.
+type AltSvcReport struct {
+	Raw      string       `json:"raw"`
+	Clear    bool         `json:"clear,omitempty"`
+	Services []AltService `json:"services,omitempty"`
+	HTTP3    bool         `json:"http3"`
+	Issues   []Issue      `json:"issues,omitempty"`
+}
+
+// defaultAltSvcMaxAge is the freshness of an alternative without ma.
+const defaultAltSvcMaxAge = 86400
+
+// cleartextProtocols are ALPN ids that run without TLS.
+var cleartextProtocols = map[string]bool{"h2c": true}
+
+// parseAltSvc parses the Alt-Svc field value: "clear" or a list of
+// protocol-id "=" quoted alt-authority, each followed by ;-parameters.
+func parseAltSvc(s string) ([]AltService, bool, *SyntaxError) {
+	if strings.TrimSpace(s) == "clear" {
+		return nil, true, nil
+	}
+	var out []AltService
+	pos := 0
+	for {
+		pos = skipWS(s, pos)
+		if pos == len(s) {
+			return out, false, nil
+		}
+		if s[pos] == ',' {
+			pos++
+			continue
+		}
+
+		end := scanToken(s, pos)
+		if end == pos {
+			return nil, false, syntaxErrorAt(s, pos, "expected a protocol id")
+		}
+		proto, err := url.PathUnescape(s[pos:end])
+		if err != nil {
+			proto = s[pos:end]
+		}
+		if end >= len(s) || s[end] != '=' {
+			return nil, false, syntaxErrorAt(s, end, "expected '=' after the protocol id")
+		}
+		pos = end + 1
+		if pos >= len(s) || s[pos] != '"' {
+			return nil, false, syntaxErrorAt(s, pos, "expected a quoted alt-authority")
+		}
+		var se *SyntaxError
+		if end, se = scanQuoted(s, pos); se != nil {
+			return nil, false, se
+		}
+		authority := s[pos+1 : end-1]
+		host, portStr, splitErr := net.SplitHostPort(authority)
+		port, convErr := strconv.Atoi(portStr)
+		if splitErr != nil || convErr != nil || port < 1 || port > 65535 {
+			return nil, false, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("invalid alt-authority %q, expected [host]:port", authority)}
+		}
+		svc := AltService{Protocol: proto, Host: host, Port: port, MaxAge: defaultAltSvcMaxAge}
+		pos = end
+
+		for {
+			pos = skipWS(s, pos)
+			if pos == len(s) || s[pos] != ';' {
+				break
+			}
+			pos = skipWS(s, pos+1)
+			end := scanToken(s, pos)
+			if end == pos {
+				return nil, false, syntaxErrorAt(s, pos, "expected a parameter name")
+			}
+			name := strings.ToLower(s[pos:end])
+			if end >= len(s) || s[end] != '=' {
+				return nil, false, syntaxErrorAt(s, end, "expected '=' after the parameter name")
+			}
+			start := end + 1
+			if pos, se = scanValue(s, start); se != nil {
+				return nil, false, se
+			}
+			val := strings.Trim(s[start:pos], `"`)
+			switch name {
+			case "ma":
+				n, err := strconv.ParseInt(val, 10, 64)
+				if err != nil || n < 0 {
+					return nil, false, &SyntaxError{Pos: start, Msg: fmt.Sprintf("invalid ma %q, expected delta-seconds", val)}
+				}
+				svc.MaxAge = n
+			case "persist":
+				svc.Persist = val == "1"
+			}
+		}
+		out = append(out, svc)
+		if pos < len(s) && s[pos] != ',' {
+			return nil, false, syntaxErrorAt(s, pos, "expected ',' between alternatives")
+		}
+	}
+}
+
+// isHTTP3 reports whether an ALPN id is HTTP/3, including the draft
+// versions (h3-29) still advertised by some servers.
+func isHTTP3(proto string) bool {
+	return proto == "h3" || strings.HasPrefix(proto, "h3-")
+}
+
+// AnalyzeAltSvc parses the Alt-Svc headers of resp. It returns nil when
+// none is present.
+func AnalyzeAltSvc(resp *http.Response) *AltSvcReport {
+	vals := resp.Header.Values("Alt-Svc")
+	if len(vals) == 0 {
+		return nil
+	}
+	r := &AltSvcReport{Raw: strings.Join(vals, ", ")}
+	services, clear, se := parseAltSvc(r.Raw)
+	if se != nil {
+		r.Issues = append(r.Issues, Issue{Severity: "medium", Message: fmt.Sprintf("unparseable, clients ignore it: %s at %d near %s", se.Msg, se.Pos, syntaxContext(r.Raw, se.Pos))})
+		return r
+	}
+	r.Services, r.Clear = services, clear
+
+	origin := ""
+	cleartextOrigin := false
+	if resp.Request != nil && resp.Request.URL != nil {
+		origin = strings.ToLower(resp.Request.URL.Hostname())
+		cleartextOrigin = resp.Request.URL.Scheme == "http"
+	}
+	if cleartextOrigin && len(services) > 0 {
+		r.Issues = append(r.Issues, Issue{Severity: "medium", Message: "advertised over cleartext HTTP, where a network attacker can inject or alter it"})
+	}
+
+	for _, svc := range services {
+		dir := svc.Protocol
+		if isHTTP3(svc.Protocol) {
+			r.HTTP3 = true
+			if svc.Protocol != "h3" {
+				r.Issues = append(r.Issues, Issue{Severity: "info", Directive: dir, Message: "draft HTTP/3 version, current clients only use h3"})
+			}
+		}
+		if cleartextProtocols[svc.Protocol] {
+			r.Issues = append(r.Issues, Issue{Severity: "medium", Directive: dir, Message: "cleartext protocol, requests to the alternative are not encrypted"})
+		}
+		if svc.Host != "" && !strings.EqualFold(svc.Host, origin) {
+			r.Issues = append(r.Issues, Issue{Severity: "low", Directive: dir, Message: fmt.Sprintf("alternative on a different host %s, which serves this origin's traffic without the URL changing", svc.Host)})
+		}
+		if svc.MaxAge == 0 {
+			r.Issues = append(r.Issues, Issue{Severity: "info", Directive: dir, Message: "ma=0, the alternative expires immediately"})
+		}
+	}
+	return r
+}
+
+func altSvcCLI(r *AltSvcReport) {
+	section("Alternative Services (Alt-Svc)")
+	if r == nil {
+		fmt.Printf(" %s %s No Alt-Svc header, HTTP/3 not advertised\n\n", "└─", green("["+tick+"]"))
+		return
+	}
+
+	switch {
+	case r.Clear:
+		fmt.Printf(" ├─ clear (previous alternatives are invalidated)\n")
+	case r.HTTP3:
+		fmt.Printf(" ├─ HTTP/3 advertised: yes\n")
+	default:
+		fmt.Printf(" ├─ HTTP/3 advertised: no\n")
+	}
+	for _, svc := range r.Services {
+		host := svc.Host
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		line := fmt.Sprintf("%s → %s:%d (ma=%d)", svc.Protocol, host, svc.Port, svc.MaxAge)
+		if svc.Persist {
+			line += " persist"
+		}
+		fmt.Printf(" ├─ %s\n", line)
+	}
+	fmt.Println(" │")
+
+	if len(r.Issues) == 0 {
+		fmt.Printf(" └─ %s No issues\n\n", green("["+tick+"]"))
+		return
+	}
+	icon := green("[" + tick + "]")
+	if hasSerious(r.Issues) {
+		icon = yellow("[" + warn + "]")
+	}
+	fmt.Printf(" └─ %s %d issue(s)\n", icon, len(r.Issues))
+	for _, is := range r.Issues {
+		fmt.Printf("    → %s\n", issueLine(is))
+	}
+	fmt.Println()
+}
@@ -0,0 +1,110 @@
+package output
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseAltSvc(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  []AltService
+		clear bool
+	}{
+		{`h3=":443"; ma=86400, h3-29=":443"`, []AltService{
+			{Protocol: "h3", Port: 443, MaxAge: 86400},
+			{Protocol: "h3-29", Port: 443, MaxAge: defaultAltSvcMaxAge},
+		}, false},
+		{`h2="alt.example.com:8443"; ma=3600; persist=1`, []AltService{
+			{Protocol: "h2", Host: "alt.example.com", Port: 8443, MaxAge: 3600, Persist: true},
+		}, false},
+		{`h3="[::1]:443";ma="60"`, []AltService{{Protocol: "h3", Host: "::1", Port: 443, MaxAge: 60}}, false},
+		{`w%3Dx=":80"`, []AltService{{Protocol: "w=x", Port: 80, MaxAge: defaultAltSvcMaxAge}}, false},
+		{`h3=":443"; foo=bar; MA=0`, []AltService{{Protocol: "h3", Port: 443, MaxAge: 0}}, false},
+		{`, h3=":443" ,`, []AltService{{Protocol: "h3", Port: 443, MaxAge: defaultAltSvcMaxAge}}, false},
+		{` clear `, nil, true},
+		{``, nil, false},
+	}
+	for _, tt := range tests {
+		got, clear, err := parseAltSvc(tt.in)
+		if err != nil {
+			t.Errorf("parseAltSvc(%q) = %v", tt.in, err)
+			continue
+		}
+		if clear != tt.clear || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseAltSvc(%q) = %+v, %v, want %+v, %v", tt.in, got, clear, tt.want, tt.clear)
+		}
+	}
+}
+
+func TestParseAltSvcInvalid(t *testing.T) {
+	tests := []struct {
+		in  string
+		pos int
+	}{
+		{`=":443"`, 0},
+		{`h3`, 2},
+		{`h3=:443`, 3},
+		{`h3=":443`, 3},
+		{`h3="443"`, 3},
+		{`h3=":0"`, 3},
+		{`h3=":65536"`, 3},
+		{`h3="host"`, 3},
+		{`h3=":443" h2=":443"`, 10},
+		{`h3=":443"; ma`, 13},
+		{`h3=":443"; =1`, 11},
+		{`h3=":443"; ma=-1`, 14},
+		{`h3=":443"; ma=soon`, 14},
+		{`h3=":443"; ma=`, 14},
+		{`clear, h3=":443"`, 5},
+	}
+	for _, tt := range tests {
+		_, _, err := parseAltSvc(tt.in)
+		if err == nil {
+			t.Errorf("parseAltSvc(%q) succeeded, want an error at %d", tt.in, tt.pos)
+			continue
+		}
+		if err.Pos != tt.pos {
+			t.Errorf("parseAltSvc(%q) = %v, want an error at %d", tt.in, err, tt.pos)
+		}
+	}
+}
+
+func TestAnalyzeAltSvc(t *testing.T) {
+	resp := func(scheme, value string) *http.Response {
+		r := &http.Response{Header: http.Header{}, Request: &http.Request{URL: &url.URL{Scheme: scheme, Host: "example.com"}}}
+		if value != "" {
+			r.Header.Set("Alt-Svc", value)
+		}
+		return r
+	}
+	if r := AnalyzeAltSvc(resp("https", "")); r != nil {
+		t.Errorf("AnalyzeAltSvc without Alt-Svc = %+v, want nil", r)
+	}
+	tests := []struct {
+		scheme, value string
+		http3         bool
+		issues        []string
+	}{
+		{"https", `h3=":443"; ma=86400`, true, nil},
+		{"https", `h3-29=":443"`, true, []string{"info"}},
+		{"http", `h3=":443"`, true, []string{"medium"}},
+		{"https", `h2c=":80"`, false, []string{"medium"}},
+		{"https", `h3="cdn.example.net:443"`, true, []string{"low"}},
+		{"https", `h3="EXAMPLE.com:443"`, true, nil},
+		{"https", `h3=":443"; ma=0`, true, []string{"info"}},
+		{"https", `h3=:443`, false, []string{"medium"}},
+	}
+	for _, tt := range tests {
+		r := AnalyzeAltSvc(resp(tt.scheme, tt.value))
+		var got []string
+		for _, is := range r.Issues {
+			got = append(got, is.Severity)
+		}
+		if r.HTTP3 != tt.http3 || !reflect.DeepEqual(got, tt.issues) {
+			t.Errorf("AnalyzeAltSvc(%s %q) = HTTP3 %v, issues %v, want %v, %v", tt.scheme, tt.value, r.HTTP3, got, tt.http3, tt.issues)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package output
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Cache verdicts, from safest to least safe.
+const (
+	CacheSafe    = "safe"
+	CachePrivate = "cacheable-private"
+	CacheShared  = "cacheable-shared"
+)
+
+// CacheDirectives maps lower-cased Cache-Control directive names to their
+// unquoted argument ("" when the directive has none).
+type CacheDirectives map[string]string
+
+// ParseCacheControl splits a Cache-Control value into its directives. When
+// a directive is repeated the first occurrence wins.
+func ParseCacheControl(raw string) CacheDirectives {
+	d := CacheDirectives{}
+	for _, part := range splitQuoted(raw, ',') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		if _, dup := d[name]; !dup {
+			d[name] = value
+		}
+	}
+	return d
+}
+
+func (d CacheDirectives) Has(name string) bool {
+	_, ok := d[name]
+	return ok
+}
+
+// unqualified reports whether name is present without a field-name list;
+// private="Set-Cookie" only restricts the listed fields.
+func (d CacheDirectives) unqualified(name string) bool {
+	v, ok := d[name]
+	return ok && v == ""
+}
+
+// splitQuoted splits s on sep outside of double-quoted strings.
+func splitQuoted(s string, sep rune) []string {
+	var (
+		out    []string
+		quoted bool
+		start  int
+	)
+	for i, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == sep && !quoted:
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, s[start:])
+}
+
+// AnalyzeCacheControl decides who may store the response (RFC 9111 §3 and
+// §3.5), taking into account a Set-Cookie in the response, an Authorization
+// header in the request and the Vary header.
+func AnalyzeCacheControl(d CacheDirectives, resp *http.Response) (string, []Issue) {
+	var issues []Issue
+	add := func(sev, dir, msg string) {
+		issues = append(issues, Issue{Severity: sev, Directive: dir, Message: msg})
+	}
+
+	if d.Has("no-store") {
+		if d.Has("public") || d.Has("max-age") || d.Has("s-maxage") {
+			add("low", "no-store", "combined with directives that allow caching, which are ignored")
+		}
+		return CacheSafe, issues
+	}
+
+	var (
+		vary       = strings.ToLower(strings.Join(resp.Header.Values("Vary"), ","))
+		setCookie  = len(resp.Header.Values("Set-Cookie")) > 0
+		authorized = resp.Request != nil && resp.Request.Header.Get("Authorization") != ""
+	)
+
+	shared := !d.unqualified("private")
+	if authorized && !(d.Has("public") || d.Has("s-maxage") || d.Has("must-revalidate")) {
+		// Shared caches must not reuse authenticated responses unless
+		// explicitly allowed to.
+		shared = false
+	}
+	if strings.Contains(vary, "*") {
+		shared = false
+	}
+
+	verdict := CachePrivate
+	if shared {
+		verdict = CacheShared
+		switch {
+		case setCookie && !d.Has("private"):
+			add("high", "", "the response sets a cookie and can be stored by shared caches, which may serve it to other users")
+		case authorized:
+			add("high", "", "an authenticated response is explicitly allowed into shared caches")
+		case strings.Contains(vary, "cookie") || strings.Contains(vary, "authorization"):
+			add("low", "", "can be stored by shared caches, keyed on the credentials listed in Vary")
+		default:
+			add("medium", "", "can be stored by shared caches (proxies, CDNs); use private or no-store for user-specific content")
+		}
+		if d.Has("public") && d.Has("private") {
+			add("low", "public", "public and private are both set")
+		}
+	} else {
+		add("low", "", "can be stored in the browser cache; use no-store for sensitive content")
+	}
+
+	if d.unqualified("no-cache") {
+		add("info", "no-cache", "stored responses must be revalidated, but are still written to the cache")
+	}
+	return verdict, issues
+}
+
+func checkCacheControl(f *RecFinding, val string, resp *http.Response, _ *Evidence) {
+	f.Verdict, f.Issues = AnalyzeCacheControl(ParseCacheControl(val), resp)
+	if f.Verdict == CacheSafe {
+		f.Status = "ok"
+	} else {
+		f.Status = "weak"
+	}
+}
+
+// verdictText turns a RecFinding verdict into the wording used in the CLI.
+func verdictText(v string) string {
+	switch v {
+	case CacheShared:
+		return "cacheable by shared caches"
+	case CachePrivate:
+		return "cacheable privately (browser cache only)"
+	}
+	return v
+}
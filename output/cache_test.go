@@ -0,0 +1,76 @@
+package output
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCacheControl(t *testing.T) {
+	tests := []struct {
+		in   string
+		want CacheDirectives
+	}{
+		{"", CacheDirectives{}},
+		{"no-store", CacheDirectives{"no-store": ""}},
+		{"Public, MAX-AGE=3600 , s-maxage=\"60\"", CacheDirectives{"public": "", "max-age": "3600", "s-maxage": "60"}},
+		{`private="Set-Cookie, Authorization", no-cache`, CacheDirectives{"private": "Set-Cookie, Authorization", "no-cache": ""}},
+		{"max-age=10, max-age=0,,", CacheDirectives{"max-age": "10"}},
+	}
+	for _, tt := range tests {
+		if got := ParseCacheControl(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseCacheControl(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeCacheControl(t *testing.T) {
+	tests := []struct {
+		cc      string
+		headers []string
+		auth    bool
+		verdict string
+		issues  []string
+	}{
+		{"no-store", nil, false, CacheSafe, nil},
+		{"no-store, max-age=3600", nil, false, CacheSafe, []string{"low"}},
+		{"private, max-age=60", nil, false, CachePrivate, []string{"low"}},
+		{"max-age=60", nil, false, CacheShared, []string{"medium"}},
+		{"public, max-age=60, private", nil, false, CachePrivate, []string{"low"}},
+		// A cookie in a response shared caches may store leaks it to other
+		// users, unless private keeps it out.
+		{"public, max-age=60", []string{"Set-Cookie: sid=1"}, false, CacheShared, []string{"high"}},
+		{`private="Set-Cookie", max-age=60`, []string{"Set-Cookie: sid=1"}, false, CacheShared, []string{"medium"}},
+		{"private", []string{"Set-Cookie: sid=1"}, false, CachePrivate, []string{"low"}},
+		// Vary: * makes every request a miss; Vary on credentials keys the
+		// stored response on them.
+		{"public, max-age=60", []string{"Set-Cookie: sid=1", "Vary: *"}, false, CachePrivate, []string{"low"}},
+		{"public, max-age=60", []string{"Vary: Accept-Encoding, Cookie"}, false, CacheShared, []string{"low"}},
+		{"max-age=60", []string{"Vary: accept-encoding", "Vary: authorization"}, false, CacheShared, []string{"low"}},
+		{"public, max-age=60", []string{"Set-Cookie: sid=1", "Vary: Cookie"}, false, CacheShared, []string{"high"}},
+		// Authenticated responses stay out of shared caches unless allowed.
+		{"max-age=60", nil, true, CachePrivate, []string{"low"}},
+		{"s-maxage=60", nil, true, CacheShared, []string{"high"}},
+		{"no-cache", nil, false, CacheShared, []string{"medium", "info"}},
+		{`no-cache="Set-Cookie"`, nil, false, CacheShared, []string{"medium"}},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}, Request: &http.Request{Header: http.Header{}}}
+		for _, h := range tt.headers {
+			name, value, _ := strings.Cut(h, ": ")
+			resp.Header.Add(name, value)
+		}
+		if tt.auth {
+			resp.Request.Header.Set("Authorization", "Bearer x")
+		}
+		verdict, issues := AnalyzeCacheControl(ParseCacheControl(tt.cc), resp)
+		var got []string
+		for _, is := range issues {
+			got = append(got, is.Severity)
+		}
+		if verdict != tt.verdict || !reflect.DeepEqual(got, tt.issues) {
+			t.Errorf("AnalyzeCacheControl(%q, %q, auth %v) = %s %v, want %s %v", tt.cc, tt.headers, tt.auth, verdict, got, tt.verdict, tt.issues)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package output
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// clearSiteDataTypes are the types defined by the Clear-Site-Data spec and
+// its extensions. Browsers match them case-sensitively.
+var clearSiteDataTypes = []string{"cache", "cookies", "storage", "executionContexts", "clientHints", "prefetchCache", "prerenderCache", "*"}
+
+// ParseClearSiteData returns the types a browser would act on. Each type must
+// be a quoted string; anything else is reported and ignored.
+func ParseClearSiteData(raw string) (types []string, issues []Issue) {
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			issues = append(issues, Issue{Severity: "low", Message: "empty list member"})
+			continue
+		}
+		quoted := len(item) >= 2 && item[0] == '"' && item[len(item)-1] == '"'
+		name := strings.Trim(item, `"`)
+		known := false
+		for _, t := range clearSiteDataTypes {
+			if name == t {
+				known = true
+				break
+			}
+		}
+		switch {
+		case !quoted && known:
+			issues = append(issues, Issue{Severity: "medium", Directive: item, Message: fmt.Sprintf("must be a quoted string (\"%s\"), browsers ignore it", name)})
+		case !quoted:
+			issues = append(issues, Issue{Severity: "medium", Directive: item, Message: "not a quoted string, browsers ignore it"})
+		case !known:
+			issues = append(issues, Issue{Severity: "low", Directive: item, Message: "unknown type, browsers ignore it"})
+		default:
+			types = append(types, name)
+		}
+	}
+	return types, issues
+}
+
+// checkClearSiteData grades the header against what the page is. Logout
+// responses should clear cookies and storage; on any other page the same
+// types silently wipe the user's state.
+func checkClearSiteData(f *RecFinding, val string, _ *http.Response, ev *Evidence) {
+	types, issues := ParseClearSiteData(val)
+	set := map[string]bool{}
+	for _, t := range types {
+		set[t] = true
+	}
+	all := set["*"]
+
+	if ev.Logout {
+		switch {
+		case len(types) == 0:
+			issues = append(issues, Issue{Severity: "high", Message: "no valid type, the header has no effect"})
+		case !all:
+			if !set["cookies"] {
+				issues = append(issues, Issue{Severity: "medium", Directive: `"cookies"`, Message: "missing, session cookies survive the logout"})
+			}
+			if !set["storage"] {
+				issues = append(issues, Issue{Severity: "low", Directive: `"storage"`, Message: "missing, localStorage, IndexedDB and service workers survive the logout"})
+			}
+			if !set["cache"] {
+				issues = append(issues, Issue{Severity: "low", Directive: `"cache"`, Message: "missing, authenticated pages stay in the browser cache"})
+			}
+		}
+	} else {
+		f.Recommended = ""
+		for _, t := range []string{"*", "cookies", "storage", "cache"} {
+			if !set[t] {
+				continue
+			}
+			switch t {
+			case "*":
+				issues = append(issues, Issue{Severity: "medium", Directive: `"*"`, Message: "clears all site data on a page that is not a logout endpoint"})
+			case "cookies":
+				issues = append(issues, Issue{Severity: "medium", Directive: `"cookies"`, Message: "logs the user out on every visit to a page that is not a logout endpoint"})
+			default:
+				issues = append(issues, Issue{Severity: "medium", Directive: `"` + t + `"`, Message: "wipes the user's " + t + " on a page that is not a logout endpoint"})
+			}
+		}
+	}
+
+	f.Issues = issues
+	if hasSerious(issues) {
+		f.Status = "weak"
+	} else {
+		f.Status = "ok"
+	}
+}
@@ -0,0 +1,139 @@
+package output
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Status values specific to Content-Type.
+const (
+	ContentNoCharset = "no-charset"
+	ContentMismatch  = "mismatch"
+)
+
+// textual reports whether a media type is decoded as text, and therefore
+// needs a charset to avoid encoding sniffing. application/json is always
+// UTF-8 and defines no charset parameter (RFC 8259 §11).
+func textual(mt string) bool {
+	switch {
+	case strings.HasPrefix(mt, "text/"),
+		mt == "application/javascript",
+		mt == "application/xml", strings.HasSuffix(mt, "+xml"):
+		return true
+	}
+	return false
+}
+
+// sniffable are the declared types browsers still sniff when nosniff is
+// absent.
+var sniffable = map[string]bool{
+	"text/plain":               true,
+	"application/octet-stream": true,
+	"unknown/unknown":          true,
+	"application/unknown":      true,
+	"*/*":                      true,
+}
+
+// compatibleTypes reports whether a sniffed media type agrees with the
+// declared one. Sniffing only recognises a handful of signatures, so generic
+// results (text/plain, application/octet-stream) never count as mismatches.
+func compatibleTypes(declared, sniffed string) bool {
+	switch {
+	case sniffed == declared, sniffed == "text/plain", sniffed == "application/octet-stream":
+		return true
+	case sniffed == "text/xml":
+		return strings.HasSuffix(declared, "xml")
+	case sniffed == "text/html":
+		return declared == "application/xhtml+xml"
+	}
+	return false
+}
+
+// placeholderTypes are declared media types that say nothing about the
+// content.
+var placeholderTypes = map[string]bool{
+	"*/*":                 true,
+	"unknown/unknown":     true,
+	"application/unknown": true,
+}
+
+// contentTypeRecommendation derives the Content-Type to recommend from the
+// declared media type in val when the body sample agrees with it, and from
+// the sniffed type otherwise. It returns "" when neither identifies the
+// content: the value is missing or invalid and the body is absent or only
+// sniffs as generic text or binary data.
+func contentTypeRecommendation(val string, ev *Evidence) string {
+	// An invalid value may still start with a usable type/subtype.
+	typ, _, _ := strings.Cut(val, ";")
+	declared, _, err := mime.ParseMediaType(typ)
+	if err != nil || !strings.Contains(declared, "/") || placeholderTypes[declared] {
+		declared = ""
+	}
+	sniffed := ""
+	if len(ev.Body) > 0 {
+		sniffed, _, _ = mime.ParseMediaType(http.DetectContentType(ev.Body))
+	}
+
+	mt := declared
+	switch {
+	case declared != "" && (sniffed == "" || compatibleTypes(declared, sniffed)):
+	case sniffed != "" && sniffed != "text/plain" && sniffed != "application/octet-stream":
+		mt = sniffed
+	case declared == "":
+		return ""
+	}
+	if textual(mt) {
+		return mt + "; charset=utf-8"
+	}
+	return mt
+}
+
+// checkContentType validates the declared media type and compares it with
+// the body sample in ev, taking X-Content-Type-Options into account.
+func checkContentType(f *RecFinding, val string, resp *http.Response, ev *Evidence) {
+	add := func(sev, dir, msg string) {
+		f.Issues = append(f.Issues, Issue{Severity: sev, Directive: dir, Message: msg})
+	}
+	nosniff := strings.EqualFold(strings.TrimSpace(resp.Header.Get("X-Content-Type-Options")), "nosniff")
+
+	mt, params, err := mime.ParseMediaType(val)
+	if err != nil {
+		f.Status = StatusInvalid
+		add("medium", "", "not a valid media type ("+err.Error()+"), browsers fall back to sniffing")
+		return
+	}
+	f.Status = "ok"
+
+	if textual(mt) {
+		if _, ok := params["charset"]; !ok {
+			f.Status = ContentNoCharset
+			if mt == "text/html" {
+				add("medium", "charset", "missing, browsers guess the encoding from the body, which enables encoding-based XSS")
+			} else {
+				add("low", "charset", "missing, browsers guess the encoding from the body")
+			}
+		}
+	}
+
+	if sniffable[mt] && !nosniff {
+		add("low", "X-Content-Type-Options", "nosniff is missing, browsers may sniff the body of a "+mt+" response")
+	}
+
+	if len(ev.Body) == 0 {
+		return
+	}
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(ev.Body))
+	if compatibleTypes(mt, sniffed) {
+		return
+	}
+	f.Status = ContentMismatch
+	switch {
+	case sniffed == "text/html" && !nosniff:
+		add("high", "", "declared as "+mt+" but the body looks like HTML, browsers may sniff and render it (XSS)")
+	case sniffed == "text/html":
+		add("low", "", "declared as "+mt+" but the body looks like HTML; nosniff keeps browsers from rendering it")
+	default:
+		add("low", "", "declared as "+mt+" but the body looks like "+sniffed)
+	}
+}
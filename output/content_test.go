@@ -0,0 +1,34 @@
+package output
+
+import "testing"
+
+func TestContentTypeRecommendation(t *testing.T) {
+	html := []byte("<!DOCTYPE html><html><body>hi</body></html>")
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	json := []byte(`{"id": 1}`)
+	tests := []struct {
+		val  string
+		body []byte
+		want string
+	}{
+		{"", html, "text/html; charset=utf-8"},
+		{"", png, "image/png"},
+		{"", json, ""},
+		{"", nil, ""},
+		{"application/json", json, "application/json"},
+		{"application/json", nil, "application/json"},
+		{"text/css", nil, "text/css; charset=utf-8"},
+		{"application/xhtml+xml", html, "application/xhtml+xml; charset=utf-8"},
+		{"application/json", html, "text/html; charset=utf-8"},
+		{"application/octet-stream", png, "image/png"},
+		{"text/html; charset", nil, "text/html; charset=utf-8"},
+		{"image/png;;=", nil, "image/png"},
+		{"*/*", json, ""},
+		{"text", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := contentTypeRecommendation(tt.val, &Evidence{Body: tt.body}); got != tt.want {
+			t.Errorf("contentTypeRecommendation(%q, %q) = %q, want %q", tt.val, tt.body, got, tt.want)
+		}
+	}
+}
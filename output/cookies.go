@@ -0,0 +1,226 @@
+package output
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CookieFinding is the attribute audit of a single Set-Cookie header. The
+// cookie value itself is never reported.
+type CookieFinding struct {
+	Name       string  `json:"name"`
+	Attributes string  `json:"attributes,omitempty"`
+	Issues     []Issue `json:"issues,omitempty"`
+}
+
+// SetCookie holds the attributes of a Set-Cookie header that matter for the
+// audit. Lifetime is zero for session cookies.
+type SetCookie struct {
+	Name       string
+	Attributes []string
+	Secure     bool
+	HttpOnly   bool
+	SameSite   string
+	HasSame    bool
+	Domain     string
+	Path       string
+	Lifetime   time.Duration
+}
+
+// sessionCookie matches names commonly used for session identifiers and
+// authentication tokens.
+var sessionCookie = regexp.MustCompile(`(?i)sess|sid$|^sid|token|auth|jwt|login|remember`)
+
+const longLivedSession = 24 * time.Hour
+
+// ParseSetCookie parses a raw Set-Cookie value (RFC 6265 §5.2). now is the
+// reference time for Expires, normally the response Date.
+func ParseSetCookie(raw string, now time.Time) SetCookie {
+	parts := strings.Split(raw, ";")
+	name, _, _ := strings.Cut(parts[0], "=")
+	c := SetCookie{Name: strings.TrimSpace(name)}
+
+	var maxAge, expires *time.Duration
+	for _, attr := range parts[1:] {
+		attr = strings.TrimSpace(attr)
+		if attr == "" {
+			continue
+		}
+		c.Attributes = append(c.Attributes, attr)
+		key, val, _ := strings.Cut(attr, "=")
+		val = strings.TrimSpace(val)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "secure":
+			c.Secure = true
+		case "httponly":
+			c.HttpOnly = true
+		case "samesite":
+			c.SameSite, c.HasSame = val, true
+		case "domain":
+			c.Domain = strings.TrimPrefix(strings.ToLower(val), ".")
+		case "path":
+			c.Path = val
+		case "max-age":
+			if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+				d := time.Duration(n) * time.Second
+				maxAge = &d
+			}
+		case "expires":
+			if t, ok := parseCookieDate(val); ok {
+				d := t.Sub(now)
+				expires = &d
+			}
+		}
+	}
+	// Max-Age takes precedence over Expires.
+	switch {
+	case maxAge != nil:
+		c.Lifetime = *maxAge
+	case expires != nil:
+		c.Lifetime = *expires
+	}
+	return c
+}
+
+func parseCookieDate(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC1123, "Mon, 02-Jan-2006 15:04:05 MST", "Mon, 02-Jan-06 15:04:05 MST", time.RFC850, time.ANSIC} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// AnalyzeCookie audits a cookie set by a response to host over scheme.
+func AnalyzeCookie(c SetCookie, host, scheme string) []Issue {
+	var issues []Issue
+	add := func(sev, attr, format string, a ...interface{}) {
+		issues = append(issues, Issue{Severity: sev, Directive: attr, Message: fmt.Sprintf(format, a...)})
+	}
+	session := sessionCookie.MatchString(c.Name)
+	lower := strings.ToLower(c.Name)
+
+	switch {
+	case strings.HasPrefix(lower, "__host-"):
+		if !c.Secure || c.Domain != "" || c.Path != "/" {
+			add("high", "__Host-", "prefix requires Secure, Path=/ and no Domain, browsers reject the cookie")
+		}
+	case strings.HasPrefix(lower, "__secure-"):
+		if !c.Secure {
+			add("high", "__Secure-", "prefix requires Secure, browsers reject the cookie")
+		}
+	}
+
+	if !c.Secure {
+		sev := "low"
+		if session {
+			sev = "medium"
+		}
+		add(sev, "Secure", "missing, the cookie is sent over plain HTTP")
+	} else if strings.EqualFold(scheme, "http") {
+		add("low", "Secure", "set over plain HTTP, browsers ignore Secure cookies from insecure origins")
+	}
+	if !c.HttpOnly {
+		sev := "low"
+		if session {
+			sev = "medium"
+		}
+		add(sev, "HttpOnly", "missing, the cookie is readable from JavaScript")
+	}
+
+	switch ss := strings.ToLower(c.SameSite); {
+	case !c.HasSame:
+		add("low", "SameSite", "missing, the browser default applies (Lax in Chromium, None elsewhere)")
+	case ss == "none" && !c.Secure:
+		add("high", "SameSite", "None without Secure, browsers reject the cookie")
+	case ss == "none":
+		add("info", "SameSite", "None, the cookie is sent on cross-site requests")
+	case ss != "lax" && ss != "strict":
+		add("low", "SameSite", "invalid value %q, treated as the browser default", c.SameSite)
+	}
+
+	if c.Domain != "" {
+		host = strings.ToLower(host)
+		switch {
+		case host != c.Domain && !strings.HasSuffix(host, "."+c.Domain):
+			add("medium", "Domain", "%s doesn't match %s, browsers reject the cookie", c.Domain, host)
+		case c.Domain != host && isPublicSuffix(c.Domain):
+			add("medium", "Domain", "%s covers a public suffix, browsers reject the cookie", c.Domain)
+		case c.Domain == RegistrableDomain(host) && c.Domain != host:
+			add("medium", "Domain", "%s shares the cookie with every subdomain of the site", c.Domain)
+		default:
+			add("low", "Domain", "%s also sends the cookie to its subdomains", c.Domain)
+		}
+	}
+
+	if session && c.Lifetime > longLivedSession {
+		add("medium", "Expires", "session cookie persists for %s", humanDuration(c.Lifetime))
+	}
+	return issues
+}
+
+func humanDuration(d time.Duration) string {
+	if days := int(d.Hours() / 24); days >= 1 {
+		return fmt.Sprintf("%d days", days)
+	}
+	return d.Round(time.Minute).String()
+}
+
+// AuditCookies audits every Set-Cookie header of resp, not just the first.
+func AuditCookies(resp *http.Response) []CookieFinding {
+	now := time.Now()
+	if t, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		now = t
+	}
+	host, scheme := "", ""
+	if resp.Request != nil && resp.Request.URL != nil {
+		host, scheme = resp.Request.URL.Hostname(), resp.Request.URL.Scheme
+	}
+
+	var out []CookieFinding
+	for _, raw := range resp.Header.Values("Set-Cookie") {
+		c := ParseSetCookie(raw, now)
+		out = append(out, CookieFinding{
+			Name:       c.Name,
+			Attributes: strings.Join(c.Attributes, "; "),
+			Issues:     AnalyzeCookie(c, host, scheme),
+		})
+	}
+	return out
+}
+
+func cookiesCLI(cookies []CookieFinding) {
+	section("Set-Cookie Attributes")
+	if len(cookies) == 0 {
+		fmt.Printf(" %s %s No cookies set\n\n", "└─", green("["+tick+"]"))
+		return
+	}
+	for idx, c := range cookies {
+		last := idx == len(cookies)-1
+		branch, vert := "├─", "│"
+		if last {
+			branch, vert = "└─", " "
+		}
+		icon := green("[" + tick + "]")
+		if hasSerious(c.Issues) {
+			icon = yellow("[" + warn + "]")
+		}
+		fmt.Printf(" %s %s %s\n", branch, icon, c.Name)
+		if c.Attributes != "" {
+			fmt.Printf(" %s  → %s\n", vert, c.Attributes)
+		}
+		if ShowRecommendedDetails {
+			for _, is := range c.Issues {
+				fmt.Printf(" %s  → %s\n", vert, issueLine(is))
+			}
+		}
+		if !last {
+			fmt.Println(" │")
+		}
+	}
+	fmt.Println()
+}
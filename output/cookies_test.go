@@ -0,0 +1,95 @@
+package output
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSetCookie(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want SetCookie
+	}{
+		{"sid=abc", SetCookie{Name: "sid"}},
+		{" sid = abc ; Secure; HTTPONLY; samesite=Strict", SetCookie{
+			Name: "sid", Attributes: []string{"Secure", "HTTPONLY", "samesite=Strict"},
+			Secure: true, HttpOnly: true, SameSite: "Strict", HasSame: true,
+		}},
+		{"a=b; Domain=.Example.COM; Path=/app;;", SetCookie{
+			Name: "a", Attributes: []string{"Domain=.Example.COM", "Path=/app"}, Domain: "example.com", Path: "/app",
+		}},
+		{"a=b; Expires=Tue, 02 Jan 2024 00:00:00 GMT", SetCookie{
+			Name: "a", Attributes: []string{"Expires=Tue, 02 Jan 2024 00:00:00 GMT"}, Lifetime: 24 * time.Hour,
+		}},
+		{"a=b; expires=Tue, 02-Jan-2024 00:00:00 GMT", SetCookie{
+			Name: "a", Attributes: []string{"expires=Tue, 02-Jan-2024 00:00:00 GMT"}, Lifetime: 24 * time.Hour,
+		}},
+		// Max-Age takes precedence over Expires, in any order.
+		{"a=b; Max-Age=60; Expires=Tue, 02 Jan 2024 00:00:00 GMT", SetCookie{
+			Name: "a", Attributes: []string{"Max-Age=60", "Expires=Tue, 02 Jan 2024 00:00:00 GMT"}, Lifetime: time.Minute,
+		}},
+		{"a=b; Max-Age=soon; Expires=never", SetCookie{Name: "a", Attributes: []string{"Max-Age=soon", "Expires=never"}}},
+		{"a=b; SameSite", SetCookie{Name: "a", Attributes: []string{"SameSite"}, HasSame: true}},
+	}
+	for _, tt := range tests {
+		if got := ParseSetCookie(tt.in, now); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSetCookie(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeCookie(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	const safe = "; Secure; HttpOnly; SameSite=Lax"
+	tests := []struct {
+		cookie, host, scheme string
+		want                 []string
+	}{
+		{"sid=1" + safe, "www.example.com", "https", nil},
+		{"theme=dark", "www.example.com", "https", []string{"low Secure", "low HttpOnly", "low SameSite"}},
+		{"sessionid=1; SameSite=Strict", "www.example.com", "https", []string{"medium Secure", "medium HttpOnly"}},
+		{"sid=1" + safe, "www.example.com", "http", []string{"low Secure"}},
+		{"sid=1; Secure; HttpOnly; SameSite=None", "www.example.com", "https", []string{"info SameSite"}},
+		{"sid=1; HttpOnly; SameSite=None", "www.example.com", "https", []string{"medium Secure", "high SameSite"}},
+		{"sid=1; Secure; HttpOnly; SameSite=Relaxed", "www.example.com", "https", []string{"low SameSite"}},
+		// Cookie prefixes.
+		{"__Host-sid=1; Path=/" + safe, "www.example.com", "https", nil},
+		{"__Host-sid=1; Path=/; Domain=example.com" + safe, "www.example.com", "https", []string{"high __Host-", "medium Domain"}},
+		{"__host-sid=1; Secure; HttpOnly; SameSite=Lax", "www.example.com", "https", []string{"high __Host-"}},
+		{"__Secure-sid=1; HttpOnly; SameSite=Lax", "www.example.com", "https", []string{"high __Secure-", "medium Secure"}},
+		// Domain scope.
+		{"sid=1; Domain=app.example.com" + safe, "app.example.com", "https", []string{"low Domain"}},
+		{"sid=1; Domain=example.com" + safe, "app.example.com", "https", []string{"medium Domain"}},
+		{"sid=1; Domain=other.example" + safe, "app.example.com", "https", []string{"medium Domain"}},
+		{"sid=1; Domain=github.io" + safe, "user.github.io", "https", []string{"medium Domain"}},
+		{"sid=1; Domain=EXAMPLE.com" + safe, "example.com", "https", []string{"low Domain"}},
+		// Lifetime of session cookies.
+		{"auth_token=1; Max-Age=2592000" + safe, "example.com", "https", []string{"medium Expires"}},
+		{"auth_token=1; Max-Age=3600" + safe, "example.com", "https", nil},
+		{"theme=dark; Max-Age=2592000" + safe, "example.com", "https", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, is := range AnalyzeCookie(ParseSetCookie(tt.cookie, now), tt.host, tt.scheme) {
+			got = append(got, is.Severity+" "+is.Directive)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AnalyzeCookie(%q, %s://%s) = %q, want %q", tt.cookie, tt.scheme, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestAuditCookies(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}, Request: &http.Request{URL: &url.URL{Scheme: "https", Host: "example.com"}}}
+	resp.Header.Set("Date", "Mon, 01 Jan 2024 00:00:00 GMT")
+	resp.Header.Add("Set-Cookie", "a=1; Secure; HttpOnly; SameSite=Lax")
+	resp.Header.Add("Set-Cookie", "session=2; Expires=Wed, 31 Jan 2024 00:00:00 GMT; Secure; HttpOnly; SameSite=Lax")
+	got := AuditCookies(resp)
+	if len(got) != 2 || got[0].Name != "a" || len(got[0].Issues) != 0 || got[1].Name != "session" || len(got[1].Issues) != 1 {
+		t.Errorf("AuditCookies = %+v, want a without issues and session with a long lifetime", got)
+	}
+}
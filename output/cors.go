@@ -0,0 +1,211 @@
+package output
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CORSReport is the passive analysis of the CORS headers of a response.
+type CORSReport struct {
+	AllowOrigin      string   `json:"allow_origin,omitempty"`
+	AllowCredentials string   `json:"allow_credentials,omitempty"`
+	AllowMethods     []string `json:"allow_methods,omitempty"`
+	AllowHeaders     []string `json:"allow_headers,omitempty"`
+	ExposeHeaders    []string `json:"expose_headers,omitempty"`
+	Issues           []Issue  `json:"issues,omitempty"`
+}
+
+// CORSProbe records a request re-sent with a crafted Origin and what the
+// server answered. Severity is only set when the origin was reflected.
+type CORSProbe struct {
+	Name             string      `json:"name"`
+	Origin           string      `json:"origin"`
+	Status           int         `json:"status,omitempty"`
+	Reflected        bool        `json:"reflected"`
+	AllowOrigin      string      `json:"allow_origin,omitempty"`
+	AllowCredentials bool        `json:"allow_credentials"`
+	Severity         string      `json:"severity,omitempty"`
+	Error            string      `json:"error,omitempty"`
+	Headers          http.Header `json:"response_headers,omitempty"`
+}
+
+// sensitiveExposed are response headers that should not be readable by
+// other origins.
+var sensitiveExposed = map[string]bool{
+	"authorization": true, "proxy-authorization": true, "set-cookie": true,
+	"x-api-key": true, "x-auth-token": true, "x-csrf-token": true,
+	"x-xsrf-token": true, "x-access-token": true, "x-refresh-token": true,
+	"www-authenticate": true,
+}
+
+var riskyMethods = map[string]string{
+	"TRACE":   "medium",
+	"CONNECT": "medium",
+	"PUT":     "info",
+	"DELETE":  "info",
+	"PATCH":   "info",
+}
+
+func headerList(h http.Header, name string) []string {
+	var out []string
+	for _, v := range h.Values(name) {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+// AnalyzeCORS inspects the Access-Control-* headers of resp. It returns nil
+// when the response carries none of them.
+func AnalyzeCORS(resp *http.Response) *CORSReport {
+	h := resp.Header
+	r := &CORSReport{
+		AllowOrigin:      strings.TrimSpace(strings.Join(h.Values("Access-Control-Allow-Origin"), ", ")),
+		AllowCredentials: strings.TrimSpace(h.Get("Access-Control-Allow-Credentials")),
+		AllowMethods:     headerList(h, "Access-Control-Allow-Methods"),
+		AllowHeaders:     headerList(h, "Access-Control-Allow-Headers"),
+		ExposeHeaders:    headerList(h, "Access-Control-Expose-Headers"),
+	}
+	if r.AllowOrigin == "" && r.AllowCredentials == "" && r.AllowMethods == nil && r.AllowHeaders == nil && r.ExposeHeaders == nil {
+		return nil
+	}
+	add := func(sev, hdr, format string, a ...interface{}) {
+		r.Issues = append(r.Issues, Issue{Severity: sev, Directive: hdr, Message: fmt.Sprintf(format, a...)})
+	}
+
+	creds := r.AllowCredentials == "true"
+	if r.AllowCredentials != "" && !creds {
+		add("low", "Access-Control-Allow-Credentials", "%q is ignored, only the literal \"true\" is valid", r.AllowCredentials)
+	}
+
+	acao := "Access-Control-Allow-Origin"
+	origin := ""
+	if resp.Request != nil {
+		origin = resp.Request.Header.Get("Origin")
+	}
+	switch {
+	case r.AllowOrigin == "":
+	case len(h.Values(acao)) > 1 || strings.Contains(r.AllowOrigin, ","):
+		add("medium", acao, "multiple values, browsers reject the response")
+	case r.AllowOrigin == "*" && creds:
+		add("medium", acao, "* with credentials is rejected by browsers, which often means origins are reflected dynamically")
+	case r.AllowOrigin == "*":
+		add("low", acao, "any origin can read the response, acceptable only for public resources")
+	case strings.EqualFold(r.AllowOrigin, "null") && creds:
+		add("high", acao, "null origin with credentials: sandboxed iframes and data: URLs can read authenticated responses")
+	case strings.EqualFold(r.AllowOrigin, "null"):
+		add("medium", acao, "null origin is trusted: sandboxed iframes and data: URLs can read the response")
+	default:
+		if strings.HasPrefix(strings.ToLower(r.AllowOrigin), "http://") {
+			add("medium", acao, "%s is an insecure origin, a network attacker can impersonate it", r.AllowOrigin)
+		}
+		if origin != "" && r.AllowOrigin == origin {
+			add("info", acao, "matches the Origin sent with the request, the server may reflect arbitrary origins")
+		}
+		if !strings.Contains(strings.ToLower(strings.Join(h.Values("Vary"), ",")), "origin") {
+			add("low", "Vary", "missing Origin while Access-Control-Allow-Origin names a specific origin, caches may serve it to other origins")
+		}
+	}
+
+	for _, m := range r.AllowMethods {
+		up := strings.ToUpper(m)
+		switch {
+		case up == "*" && creds:
+			add("low", "Access-Control-Allow-Methods", "* is treated literally on credentialed requests")
+		case up == "*":
+			add("medium", "Access-Control-Allow-Methods", "* allows every method")
+		case riskyMethods[up] != "":
+			add(riskyMethods[up], "Access-Control-Allow-Methods", "%s is allowed cross-origin", up)
+		}
+	}
+	for _, hdr := range r.AllowHeaders {
+		if hdr == "*" && !creds {
+			add("low", "Access-Control-Allow-Headers", "* allows every request header")
+		}
+	}
+	for _, hdr := range r.ExposeHeaders {
+		switch {
+		case hdr == "*" && !creds:
+			add("medium", "Access-Control-Expose-Headers", "* exposes every response header to other origins")
+		case sensitiveExposed[strings.ToLower(hdr)]:
+			add("medium", "Access-Control-Expose-Headers", "%s is exposed to other origins", hdr)
+		}
+	}
+	return r
+}
+
+func corsCLI(r *CORSReport) {
+	section("CORS Configuration")
+	if r == nil {
+		fmt.Printf(" %s %s No CORS headers\n\n", "└─", green("["+tick+"]"))
+		return
+	}
+
+	observed := [][2]string{
+		{"Access-Control-Allow-Origin", r.AllowOrigin},
+		{"Access-Control-Allow-Credentials", r.AllowCredentials},
+		{"Access-Control-Allow-Methods", strings.Join(r.AllowMethods, ", ")},
+		{"Access-Control-Allow-Headers", strings.Join(r.AllowHeaders, ", ")},
+		{"Access-Control-Expose-Headers", strings.Join(r.ExposeHeaders, ", ")},
+	}
+	for _, kv := range observed {
+		if kv[1] != "" {
+			fmt.Printf(" ├─ %s: %s\n", kv[0], kv[1])
+		}
+	}
+	fmt.Println(" │")
+
+	if len(r.Issues) == 0 {
+		fmt.Printf(" └─ %s No issues\n\n", green("["+tick+"]"))
+		return
+	}
+	icon := green("[" + tick + "]")
+	if hasSerious(r.Issues) {
+		icon = yellow("[" + warn + "]")
+	}
+	fmt.Printf(" └─ %s %d issue(s)\n", icon, len(r.Issues))
+	for _, is := range r.Issues {
+		fmt.Printf("    → %s\n", issueLine(is))
+	}
+	fmt.Println()
+}
+
+func corsProbesCLI(probes []CORSProbe) {
+	section("CORS Origin Probes")
+	for idx, p := range probes {
+		last := idx == len(probes)-1
+		branch, vert := "├─", "│"
+		if last {
+			branch, vert = "└─", " "
+		}
+
+		icon := green("[" + tick + "]")
+		detail := "not reflected"
+		switch {
+		case p.Error != "":
+			icon = yellow("[" + warn + "]")
+			detail = "request failed: " + p.Error
+		case p.Reflected:
+			icon = yellow("[" + warn + "]")
+			if p.Severity == "high" {
+				icon = red("[" + cross + "]")
+			}
+			detail = fmt.Sprintf("[%s] reflected without credentials", p.Severity)
+			if p.AllowCredentials {
+				detail = fmt.Sprintf("[%s] reflected with credentials allowed", p.Severity)
+			}
+		case p.AllowOrigin != "":
+			detail = "answered with Access-Control-Allow-Origin: " + p.AllowOrigin
+		}
+		fmt.Printf(" %s %s %s: %s\n", branch, icon, p.Name, p.Origin)
+		fmt.Printf(" %s  → %s\n", vert, detail)
+		if !last {
+			fmt.Println(" │")
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeCORS(t *testing.T) {
+	if r := AnalyzeCORS(&http.Response{Header: http.Header{"Vary": {"Origin"}}}); r != nil {
+		t.Errorf("AnalyzeCORS without CORS headers = %+v, want nil", r)
+	}
+	tests := []struct {
+		origin  string
+		headers []string
+		want    []string
+	}{
+		{"", []string{"Access-Control-Allow-Origin: https://app.example", "Vary: Accept-Encoding, Origin"}, nil},
+		{"", []string{"Access-Control-Allow-Origin: https://app.example"}, []string{"low Vary"}},
+		{"", []string{"Access-Control-Allow-Origin: *"}, []string{"low Access-Control-Allow-Origin"}},
+		{"", []string{"Access-Control-Allow-Origin: *", "Access-Control-Allow-Credentials: true"}, []string{"medium Access-Control-Allow-Origin"}},
+		{"", []string{"Access-Control-Allow-Origin: https://a.example", "Access-Control-Allow-Origin: https://b.example"}, []string{"medium Access-Control-Allow-Origin"}},
+		{"", []string{"Access-Control-Allow-Origin: https://a.example, https://b.example"}, []string{"medium Access-Control-Allow-Origin"}},
+		{"", []string{"Access-Control-Allow-Origin: http://app.example", "Vary: origin"}, []string{"medium Access-Control-Allow-Origin"}},
+		// The null origin, reflected or configured, with and without
+		// credentials.
+		{"null", []string{"Access-Control-Allow-Origin: null", "Access-Control-Allow-Credentials: true", "Vary: Origin"}, []string{"high Access-Control-Allow-Origin"}},
+		{"null", []string{"Access-Control-Allow-Origin: null"}, []string{"medium Access-Control-Allow-Origin"}},
+		{"", []string{"Access-Control-Allow-Origin: NULL"}, []string{"medium Access-Control-Allow-Origin"}},
+		// A reflected origin is only a hint without a probe.
+		{"https://evil.example", []string{"Access-Control-Allow-Origin: https://evil.example", "Vary: Origin"}, []string{"info Access-Control-Allow-Origin"}},
+		{"", []string{"Access-Control-Allow-Credentials: yes"}, []string{"low Access-Control-Allow-Credentials"}},
+		{"", []string{"Access-Control-Allow-Methods: GET, put, TRACE"}, []string{"info Access-Control-Allow-Methods", "medium Access-Control-Allow-Methods"}},
+		{"", []string{"Access-Control-Allow-Methods: *"}, []string{"medium Access-Control-Allow-Methods"}},
+		{"", []string{"Access-Control-Allow-Methods: *", "Access-Control-Allow-Credentials: true"}, []string{"low Access-Control-Allow-Methods"}},
+		{"", []string{"Access-Control-Allow-Headers: *"}, []string{"low Access-Control-Allow-Headers"}},
+		{"", []string{"Access-Control-Allow-Headers: *", "Access-Control-Allow-Credentials: true"}, nil},
+		{"", []string{"Access-Control-Expose-Headers: X-Request-Id, Set-Cookie", "Access-Control-Expose-Headers: x-csrf-token"}, []string{"medium Access-Control-Expose-Headers", "medium Access-Control-Expose-Headers"}},
+		{"", []string{"Access-Control-Expose-Headers: *"}, []string{"medium Access-Control-Expose-Headers"}},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}, Request: &http.Request{Header: http.Header{}}}
+		for _, h := range tt.headers {
+			name, value, _ := strings.Cut(h, ": ")
+			resp.Header.Add(name, value)
+		}
+		if tt.origin != "" {
+			resp.Request.Header.Set("Origin", tt.origin)
+		}
+		r := AnalyzeCORS(resp)
+		if r == nil {
+			t.Errorf("AnalyzeCORS(%q) = nil", tt.headers)
+			continue
+		}
+		var got []string
+		for _, is := range r.Issues {
+			got = append(got, is.Severity+" "+is.Directive)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AnalyzeCORS(Origin %q, %q) = %q, want %q", tt.origin, tt.headers, got, tt.want)
+		}
+	}
+}
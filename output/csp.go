@@ -0,0 +1,364 @@
+package output
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CSP is a parsed Content-Security-Policy. Directive names are lower-cased;
+// source expressions keep their original spelling.
+type CSP struct {
+	Directives map[string][]string
+	Order      []string
+	Duplicates []string
+}
+
+// cspFallback lists, for each fetch directive, the directives consulted when
+// it is absent, in order. default-src is always the last resort.
+var cspFallback = map[string][]string{
+	"script-src-elem": {"script-src"},
+	"script-src-attr": {"script-src"},
+	"style-src-elem":  {"style-src"},
+	"style-src-attr":  {"style-src"},
+	"worker-src":      {"child-src", "script-src"},
+	"frame-src":       {"child-src"},
+	"child-src":       {},
+	"script-src":      {},
+	"style-src":       {},
+	"img-src":         {},
+	"font-src":        {},
+	"connect-src":     {},
+	"media-src":       {},
+	"object-src":      {},
+	"manifest-src":    {},
+}
+
+var cspKnown = map[string]bool{
+	"default-src": true, "base-uri": true, "form-action": true, "frame-ancestors": true,
+	"sandbox": true, "upgrade-insecure-requests": true, "block-all-mixed-content": true,
+	"report-uri": true, "report-to": true, "require-trusted-types-for": true,
+	"trusted-types": true, "plugin-types": true, "navigate-to": true,
+	"prefetch-src": true, "fenced-frame-src": true, "webrtc": true,
+}
+
+func init() {
+	for d := range cspFallback {
+		cspKnown[d] = true
+	}
+}
+
+// ParseCSP parses a single serialized policy as described in CSP Level 3.
+// Empty directives are skipped and, as browsers do, only the first
+// occurrence of a repeated directive is kept.
+func ParseCSP(raw string) CSP {
+	p := CSP{Directives: map[string][]string{}}
+	for _, part := range strings.Split(raw, ";") {
+		tokens := strings.Fields(part)
+		if len(tokens) == 0 {
+			continue
+		}
+		name := strings.ToLower(tokens[0])
+		if _, dup := p.Directives[name]; dup {
+			p.Duplicates = append(p.Duplicates, name)
+			continue
+		}
+		p.Directives[name] = tokens[1:]
+		p.Order = append(p.Order, name)
+	}
+	return p
+}
+
+// Has reports whether the directive is explicitly present in the policy.
+func (p CSP) Has(name string) bool {
+	_, ok := p.Directives[name]
+	return ok
+}
+
+// Effective returns the source list that governs the given directive after
+// applying the fallback chain, and the name of the directive it came from.
+// It returns an empty name when nothing in the policy applies.
+func (p CSP) Effective(name string) ([]string, string) {
+	if src, ok := p.Directives[name]; ok {
+		return src, name
+	}
+	chain, fetch := cspFallback[name]
+	if !fetch {
+		return nil, ""
+	}
+	for _, d := range chain {
+		if src, ok := p.Directives[d]; ok {
+			return src, d
+		}
+	}
+	if src, ok := p.Directives["default-src"]; ok {
+		return src, "default-src"
+	}
+	return nil, ""
+}
+
+// cspSources summarises a source list.
+type cspSources struct {
+	none, self, unsafeInline, unsafeEval, unsafeHashes, strictDynamic bool
+	nonce, hash                                                       bool
+	wildcard, httpScheme, httpsScheme, dataScheme, insecureHosts      []string
+}
+
+func classifySources(list []string) cspSources {
+	var s cspSources
+	for _, src := range list {
+		l := strings.ToLower(src)
+		switch {
+		case l == "'none'":
+			s.none = true
+		case l == "'self'":
+			s.self = true
+		case l == "'unsafe-inline'":
+			s.unsafeInline = true
+		case l == "'unsafe-eval'":
+			s.unsafeEval = true
+		case l == "'unsafe-hashes'":
+			s.unsafeHashes = true
+		case l == "'strict-dynamic'":
+			s.strictDynamic = true
+		case strings.HasPrefix(l, "'nonce-"):
+			s.nonce = true
+		case strings.HasPrefix(l, "'sha256-"), strings.HasPrefix(l, "'sha384-"), strings.HasPrefix(l, "'sha512-"):
+			s.hash = true
+		case l == "*":
+			s.wildcard = append(s.wildcard, src)
+		case l == "http:":
+			s.httpScheme = append(s.httpScheme, src)
+		case l == "https:":
+			s.httpsScheme = append(s.httpsScheme, src)
+		case l == "data:" || l == "blob:" || l == "filesystem:":
+			s.dataScheme = append(s.dataScheme, src)
+		case strings.HasPrefix(l, "http://"), strings.HasPrefix(l, "ws://"):
+			s.insecureHosts = append(s.insecureHosts, src)
+		}
+	}
+	return s
+}
+
+// scriptDirectives govern scripts: script-src-elem covers <script> elements
+// and script-src-attr inline event handlers, both falling back to
+// script-src.
+var scriptDirectives = []string{"script-src", "script-src-elem", "script-src-attr"}
+
+// AnalyzeCSP reports the weaknesses of a parsed policy: unsafe script
+// sources, wildcards, and missing object-src, base-uri and frame-ancestors,
+// followed by unknown and duplicate directives.
+func AnalyzeCSP(p CSP) []Issue {
+	var issues []Issue
+	for _, w := range cspWeaknesses(p) {
+		issues = append(issues, w.Issue)
+	}
+	return append(issues, cspHygiene(p)...)
+}
+
+// analyzeEnforced analyses the enforced policies of h. A resource loads only
+// if every policy allows it, so a weakness is reported only when all the
+// policies share it, by the most specific issue among those stating it.
+// Unknown and duplicate directives are reported for each policy.
+func analyzeEnforced(h http.Header) []Issue {
+	policies := enforcedPolicies(h)
+	switch len(policies) {
+	case 0:
+		return AnalyzeCSP(ParseCSP(""))
+	case 1:
+		return AnalyzeCSP(policies[0])
+	}
+
+	all := make([][]cspWeakness, len(policies))
+	shared := map[string]int{}
+	for i, p := range policies {
+		all[i] = cspWeaknesses(p)
+		seen := map[string]bool{}
+		for _, w := range all[i] {
+			for _, k := range w.keys {
+				if !seen[k] {
+					seen[k] = true
+					shared[k]++
+				}
+			}
+		}
+	}
+	best := map[string]*cspWeakness{}
+	for i := range all {
+		for j := range all[i] {
+			w := &all[i][j]
+			for _, k := range w.keys {
+				if shared[k] == len(policies) && (best[k] == nil || len(w.keys) < len(best[k].keys)) {
+					best[k] = w
+				}
+			}
+		}
+	}
+	var issues []Issue
+	reported := map[*cspWeakness]bool{}
+	for i := range all {
+		for j := range all[i] {
+			w := &all[i][j]
+			for _, k := range w.keys {
+				if best[k] == w && !reported[w] {
+					reported[w] = true
+					issues = append(issues, w.Issue)
+				}
+			}
+		}
+	}
+	for _, p := range policies {
+		issues = append(issues, cspHygiene(p)...)
+	}
+	return issues
+}
+
+// cspWeakness is a weakness of a single policy. Its keys name what it allows
+// as "directive/kind" pairs, independently of the wording and, for scripts,
+// of the directive the sources fell back to, so that the weaknesses of
+// several policies can be compared.
+type cspWeakness struct {
+	Issue
+	keys []string
+}
+
+// scriptKinds are the weaknesses of a script directive; a missing one has
+// them all. Host kinds do not apply to script-src-attr, which never loads
+// URLs.
+var scriptKinds = []string{"inline", "eval", "any-host", "plain-http", "data"}
+
+func isHostKind(kind string) bool {
+	return kind == "any-host" || kind == "plain-http" || kind == "data"
+}
+
+func cspWeaknesses(p CSP) []cspWeakness {
+	var weaknesses []cspWeakness
+	add := func(keys []string, sev, dir, format string, a ...interface{}) {
+		weaknesses = append(weaknesses, cspWeakness{Issue{Severity: sev, Directive: dir, Message: fmt.Sprintf(format, a...)}, keys})
+	}
+
+	// Each script directive is enforced on its own, so a strict script-src
+	// does not make up for 'unsafe-inline' in script-src-elem. Directives
+	// falling back to the same list are checked once, for all of them.
+	governs := map[string][]string{}
+	var froms []string
+	for _, dir := range scriptDirectives {
+		_, from := p.Effective(dir)
+		if _, ok := governs[from]; !ok {
+			froms = append(froms, from)
+		}
+		governs[from] = append(governs[from], dir)
+	}
+	checked := map[string]bool{}
+	for _, from := range froms {
+		checked[from] = true
+		keys := func(kinds ...string) []string {
+			var out []string
+			for _, dir := range governs[from] {
+				for _, kind := range kinds {
+					if dir != "script-src-attr" || !isHostKind(kind) {
+						out = append(out, dir+"/"+kind)
+					}
+				}
+			}
+			return out
+		}
+		if from == "" {
+			add(keys(scriptKinds...), "high", "script-src", "neither script-src nor default-src is set, scripts from any origin are allowed")
+			continue
+		}
+		s := classifySources(p.Directives[from])
+		trusted := s.nonce || s.hash
+		switch {
+		case s.unsafeInline && !trusted:
+			add(keys("inline"), "high", from, "'unsafe-inline' without a nonce or hash allows inline script injection")
+		case s.unsafeInline:
+			add(keys("ignored-inline"), "info", from, "'unsafe-inline' is ignored by CSP2+ browsers because a nonce or hash is present")
+		}
+		if s.unsafeEval {
+			add(keys("eval"), "medium", from, "'unsafe-eval' allows eval() and similar string-to-code sinks")
+		}
+		if s.unsafeHashes {
+			add(keys("hashes"), "low", from, "'unsafe-hashes' allows hashed inline event handlers")
+		}
+		if s.strictDynamic && !trusted {
+			add(keys("ineffective-strict-dynamic"), "low", from, "'strict-dynamic' has no effect without a nonce or hash")
+		}
+		// With 'strict-dynamic' and a nonce/hash, host and scheme sources are
+		// ignored by modern browsers and only serve as a legacy fallback.
+		// script-src-attr only governs inline handlers, never URLs.
+		if from != "script-src-attr" && !(s.strictDynamic && trusted) {
+			for _, w := range append(s.wildcard, s.httpsScheme...) {
+				add(keys("any-host"), "high", from, "%s allows scripts from any host", w)
+			}
+			for _, w := range s.httpScheme {
+				add(keys("plain-http"), "high", from, "%s allows scripts from any host over plain HTTP", w)
+			}
+			for _, w := range s.dataScheme {
+				add(keys("data"), "high", from, "%s allows scripts from attacker-controlled URLs", w)
+			}
+		}
+	}
+
+	for _, dir := range p.Order {
+		if checked[dir] {
+			continue
+		}
+		s := classifySources(p.Directives[dir])
+		for _, w := range append(s.wildcard, s.httpsScheme...) {
+			add([]string{dir + "/any-host"}, "medium", dir, "%s allows any host", w)
+		}
+		for _, w := range s.httpScheme {
+			add([]string{dir + "/plain-http"}, "medium", dir, "%s allows any host over plain HTTP", w)
+		}
+	}
+	for _, dir := range p.Order {
+		for _, h := range classifySources(p.Directives[dir]).insecureHosts {
+			add([]string{dir + "/insecure " + strings.ToLower(h)}, "medium", dir, "%s is loaded over an insecure scheme", h)
+		}
+	}
+
+	plugins := []string{"object-src/plugins"}
+	if objects, objFrom := p.Effective("object-src"); objFrom == "" {
+		add(plugins, "medium", "object-src", "missing, plugins can be loaded from any origin (set object-src 'none')")
+	} else if s := classifySources(objects); !s.none {
+		add(plugins, "medium", objFrom, "plugins are not disabled (set object-src 'none')")
+	}
+	if !p.Has("base-uri") {
+		add([]string{"base-uri/missing"}, "medium", "base-uri", "missing, injected <base> tags can redirect relative script URLs")
+	}
+	framing := []string{"frame-ancestors/any-origin"}
+	if !p.Has("frame-ancestors") {
+		add(framing, "medium", "frame-ancestors", "missing, the page can be framed by any origin unless X-Frame-Options is set")
+	} else if s := classifySources(p.Directives["frame-ancestors"]); len(s.wildcard)+len(s.httpsScheme)+len(s.httpScheme) > 0 {
+		add(framing, "medium", "frame-ancestors", "allows framing by any origin")
+	}
+	return weaknesses
+}
+
+// cspHygiene reports the directives browsers ignore: unknown ones and
+// repeated occurrences.
+func cspHygiene(p CSP) []Issue {
+	var issues []Issue
+	add := func(sev, dir, format string, a ...interface{}) {
+		issues = append(issues, Issue{Severity: sev, Directive: dir, Message: fmt.Sprintf(format, a...)})
+	}
+	for _, dir := range p.Order {
+		if !cspKnown[dir] {
+			add("low", dir, "unknown directive, ignored by browsers")
+		}
+	}
+	for _, dir := range p.Duplicates {
+		add("low", dir, "duplicate directive, only the first occurrence is enforced")
+	}
+	return issues
+}
+
+func checkCSP(f *RecFinding, _ string, resp *http.Response, _ *Evidence) {
+	f.Issues = analyzeEnforced(resp.Header)
+	if hasSerious(f.Issues) {
+		f.Status = "weak"
+	} else {
+		f.Status = "ok"
+	}
+}
@@ -0,0 +1,163 @@
+package output
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseCSP(t *testing.T) {
+	tests := []struct {
+		in   string
+		want CSP
+	}{
+		{"", CSP{Directives: map[string][]string{}}},
+		{"default-src 'self'; Script-Src 'nonce-AbC' https://CDN.example", CSP{
+			Directives: map[string][]string{"default-src": {"'self'"}, "script-src": {"'nonce-AbC'", "https://CDN.example"}},
+			Order:      []string{"default-src", "script-src"},
+		}},
+		{" ;; upgrade-insecure-requests ;\tobject-src  'none' ", CSP{
+			Directives: map[string][]string{"upgrade-insecure-requests": {}, "object-src": {"'none'"}},
+			Order:      []string{"upgrade-insecure-requests", "object-src"},
+		}},
+		// Only the first occurrence of a directive is kept.
+		{"script-src 'self'; SCRIPT-SRC *", CSP{
+			Directives: map[string][]string{"script-src": {"'self'"}},
+			Order:      []string{"script-src"},
+			Duplicates: []string{"script-src"},
+		}},
+	}
+	for _, tt := range tests {
+		if got := ParseCSP(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseCSP(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCSPEffective(t *testing.T) {
+	tests := []struct {
+		policy, dir string
+		want        []string
+		from        string
+	}{
+		{"script-src 'self'", "script-src", []string{"'self'"}, "script-src"},
+		{"script-src 'self'", "script-src-elem", []string{"'self'"}, "script-src"},
+		{"default-src 'none'; script-src 'self'", "script-src-attr", []string{"'self'"}, "script-src"},
+		{"default-src 'none'", "script-src-elem", []string{"'none'"}, "default-src"},
+		{"default-src 'none'; child-src a.example", "worker-src", []string{"a.example"}, "child-src"},
+		{"default-src 'none'; script-src b.example", "worker-src", []string{"b.example"}, "script-src"},
+		{"default-src 'none'; child-src a.example", "frame-src", []string{"a.example"}, "child-src"},
+		{"default-src 'none'; style-src 'self'", "style-src-attr", []string{"'self'"}, "style-src"},
+		{"default-src 'none'", "img-src", []string{"'none'"}, "default-src"},
+		{"img-src 'self'", "script-src", nil, ""},
+		// Non-fetch directives never fall back to default-src.
+		{"default-src 'none'", "base-uri", nil, ""},
+		{"default-src 'none'", "frame-ancestors", nil, ""},
+		{"default-src 'none'; form-action 'self'", "form-action", []string{"'self'"}, "form-action"},
+	}
+	for _, tt := range tests {
+		got, from := ParseCSP(tt.policy).Effective(tt.dir)
+		if from != tt.from || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Effective(%q) of %q = %q from %q, want %q from %q", tt.dir, tt.policy, got, from, tt.want, tt.from)
+		}
+	}
+}
+
+func TestAnalyzeCSP(t *testing.T) {
+	const rest = "; object-src 'none'; base-uri 'none'; frame-ancestors 'none'"
+	tests := []struct {
+		policy string
+		want   []string
+	}{
+		{"default-src 'self'" + rest, nil},
+		{"script-src 'nonce-abc' 'strict-dynamic' https: 'unsafe-inline'" + rest,
+			[]string{"[info] script-src: 'unsafe-inline' is ignored by CSP2+ browsers because a nonce or hash is present"}},
+		{"script-src 'self' 'unsafe-inline' 'unsafe-eval'" + rest, []string{
+			"[high] script-src: 'unsafe-inline' without a nonce or hash allows inline script injection",
+			"[medium] script-src: 'unsafe-eval' allows eval() and similar string-to-code sinks",
+		}},
+		{"script-src 'self' 'strict-dynamic' data:" + rest, []string{
+			"[low] script-src: 'strict-dynamic' has no effect without a nonce or hash",
+			"[high] script-src: data: allows scripts from attacker-controlled URLs",
+		}},
+		// Each script directive is checked on its own.
+		{"script-src 'self'; script-src-elem 'self' http:; script-src-attr 'unsafe-hashes' *" + rest, []string{
+			"[high] script-src-elem: http: allows scripts from any host over plain HTTP",
+			"[low] script-src-attr: 'unsafe-hashes' allows hashed inline event handlers",
+		}},
+		{"default-src 'self'; img-src *; connect-src http://api.example" + rest, []string{
+			"[medium] img-src: * allows any host",
+			"[medium] connect-src: http://api.example is loaded over an insecure scheme",
+		}},
+		{"img-src 'self'", []string{
+			"[high] script-src: neither script-src nor default-src is set, scripts from any origin are allowed",
+			"[medium] object-src: missing, plugins can be loaded from any origin (set object-src 'none')",
+			"[medium] base-uri: missing, injected <base> tags can redirect relative script URLs",
+			"[medium] frame-ancestors: missing, the page can be framed by any origin unless X-Frame-Options is set",
+		}},
+		{"default-src 'self'; base-uri 'none'; frame-ancestors https:", []string{
+			"[medium] frame-ancestors: https: allows any host",
+			"[medium] default-src: plugins are not disabled (set object-src 'none')",
+			"[medium] frame-ancestors: allows framing by any origin",
+		}},
+		{"default-src 'self'; script-src 'self'; script-src *; sript-src 'none'" + rest, []string{
+			"[low] sript-src: unknown directive, ignored by browsers",
+			"[low] script-src: duplicate directive, only the first occurrence is enforced",
+		}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, is := range AnalyzeCSP(ParseCSP(tt.policy)) {
+			got = append(got, issueLine(is))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AnalyzeCSP(%q) = %q, want %q", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeEnforcedPolicies(t *testing.T) {
+	// rest keeps the other checks quiet so only script issues remain.
+	const rest = "; object-src 'none'; base-uri 'none'; frame-ancestors 'none'"
+	tests := []struct {
+		policies []string
+		want     []string
+	}{
+		// Equivalent weaknesses worded differently are still shared.
+		{[]string{"script-src *" + rest, "script-src https:" + rest},
+			[]string{"[high] script-src: * allows scripts from any host"}},
+		// The same wording on different directives is not.
+		{[]string{"script-src 'self'; img-src *" + rest, "script-src 'self'; media-src *" + rest}, nil},
+		{[]string{"script-src 'self'; img-src *" + rest, "script-src 'self'; img-src https:" + rest},
+			[]string{"[medium] img-src: * allows any host"}},
+		// A fallback shares the weakness with the directive it governs, the
+		// most specific issue is reported.
+		{[]string{"default-src 'self' 'unsafe-inline'" + rest, "script-src 'self'; script-src-elem 'unsafe-inline'" + rest},
+			[]string{"[high] script-src-elem: 'unsafe-inline' without a nonce or hash allows inline script injection"}},
+		{[]string{rest[2:], "script-src *" + rest},
+			[]string{"[high] script-src: * allows scripts from any host"}},
+		{[]string{"script-src 'unsafe-inline'" + rest, "script-src 'nonce-abc' 'unsafe-inline'" + rest},
+			nil},
+		{[]string{"script-src 'self'; base-uri 'none'; frame-ancestors 'none'", "script-src 'self'; object-src 'self'; base-uri 'none'; frame-ancestors 'none'"},
+			[]string{"[medium] object-src: missing, plugins can be loaded from any origin (set object-src 'none')"}},
+		{[]string{"script-src http://a.example" + rest, "script-src http://b.example" + rest}, nil},
+		{[]string{"script-src 'self' http://A.example" + rest, "script-src http://a.example" + rest},
+			[]string{"[medium] script-src: http://A.example is loaded over an insecure scheme"}},
+		// Hygiene issues are reported for each policy.
+		{[]string{"script-src 'self'; foo" + rest, "script-src 'self'; bar" + rest},
+			[]string{"[low] foo: unknown directive, ignored by browsers", "[low] bar: unknown directive, ignored by browsers"}},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		for _, p := range tt.policies {
+			h.Add("Content-Security-Policy", p)
+		}
+		var got []string
+		for _, is := range analyzeEnforced(h) {
+			got = append(got, issueLine(is))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("analyzeEnforced(%q) = %q, want %q", tt.policies, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package output
+
+import (
+	"net"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// RegistrableDomain returns the eTLD+1 of host according to the Public
+// Suffix List, e.g. "example.co.uk" for "www.example.co.uk". IP addresses,
+// single-label hosts and public suffixes are returned unchanged.
+func RegistrableDomain(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	apex, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return apex
+}
+
+// isPublicSuffix reports whether domain is on the Public Suffix List, such
+// as "com", "co.uk" or "github.io".
+func isPublicSuffix(domain string) bool {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	suffix, _ := publicsuffix.PublicSuffix(domain)
+	return suffix == domain
+}
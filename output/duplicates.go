@@ -0,0 +1,265 @@
+package output
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DuplicateFinding is a header sent more than once. Kind is duplicate
+// (identical values), conflict (different values) or combined (several CSP
+// policies enforced together).
+type DuplicateFinding struct {
+	Header   string   `json:"header"`
+	Kind     string   `json:"kind"`
+	Values   []string `json:"values"`
+	Severity string   `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// repeatable headers are lists or per-instance headers that are expected to
+// appear several times.
+var repeatable = map[string]bool{
+	"Set-Cookie":                          true,
+	"Link":                                true,
+	"Vary":                                true,
+	"Via":                                 true,
+	"Warning":                             true,
+	"Www-Authenticate":                    true,
+	"Server-Timing":                       true,
+	"Report-To":                           true,
+	"Reporting-Endpoints":                 true,
+	"Permissions-Policy":                  true,
+	"Alt-Svc":                             true,
+	"Cache-Control":                       true,
+	"Content-Security-Policy-Report-Only": true,
+}
+
+// singletons maps headers that must appear once to what browsers do with
+// conflicting instances.
+var singletons = map[string]string{
+	"Strict-Transport-Security":        "browsers only process the first value",
+	"X-Content-Type-Options":           "browsers only honour nosniff when it is the first value",
+	"Content-Type":                     "browsers use the last valid media type",
+	"X-Frame-Options":                  "browsers block all framing if any value is valid, otherwise the header is ignored",
+	"Referrer-Policy":                  "browsers apply the last recognised policy",
+	"Access-Control-Allow-Credentials": "browsers only accept the literal value true",
+	"Location":                         "clients may follow either location",
+	"Content-Length":                   "the message framing is ambiguous (request smuggling risk)",
+	"Nel":                              "browsers only process the first policy",
+}
+
+// invalidOnRepeat are headers whose combined value is invalid even when all
+// instances are identical, so the header stops working altogether.
+var invalidOnRepeat = map[string]string{
+	"Access-Control-Allow-Origin":  "browsers reject the CORS response",
+	"Cross-Origin-Opener-Policy":   "the combined value is not a valid structured field, browsers fall back to unsafe-none",
+	"Cross-Origin-Embedder-Policy": "the combined value is not a valid structured field, browsers fall back to unsafe-none",
+	"Cross-Origin-Resource-Policy": "the combined value is invalid, the header is ignored",
+}
+
+// listConflicts find contradicting directives across the instances of
+// list-valued headers, which are otherwise expected to repeat. They return
+// the severity and a description of the conflict, or false.
+var listConflicts = map[string]func(vals []string) (string, string, bool){
+	"Cache-Control": cacheControlConflicts,
+}
+
+// cacheControlConflicts reports Cache-Control instances that disagree: a
+// directive forbidding storage next to one allowing it, or one directive
+// with different values.
+func cacheControlConflicts(vals []string) (string, string, bool) {
+	dirs := map[string][]string{}
+	var order []string
+	for _, v := range vals {
+		for _, d := range strings.Split(v, ",") {
+			name, val, _ := strings.Cut(d, "=")
+			if name = strings.ToLower(strings.TrimSpace(name)); name == "" {
+				continue
+			}
+			val = strings.Trim(strings.TrimSpace(val), `"`)
+			if _, seen := dirs[name]; !seen {
+				order = append(order, name)
+			}
+			if !slices.Contains(dirs[name], val) {
+				dirs[name] = append(dirs[name], val)
+			}
+		}
+	}
+
+	// storing are the directives that let a cache keep the response.
+	var storing []string
+	for _, name := range []string{"public", "max-age", "s-maxage", "immutable"} {
+		for _, val := range dirs[name] {
+			if val != "0" {
+				storing = append(storing, strings.TrimSuffix(name+"="+val, "="))
+			}
+		}
+	}
+	var conflicts []string
+	severity := "low"
+	for _, restrictive := range []string{"no-store", "private"} {
+		if _, ok := dirs[restrictive]; ok && len(storing) > 0 {
+			conflicts = append(conflicts, restrictive+" vs "+strings.Join(storing, ", "))
+			severity = "medium"
+		}
+	}
+	for _, name := range order {
+		if len(dirs[name]) > 1 {
+			conflicts = append(conflicts, name+"="+strings.Join(dirs[name], " vs "))
+		}
+	}
+	if len(conflicts) == 0 {
+		return "", "", false
+	}
+	return severity, "conflicting directives (" + strings.Join(conflicts, "; ") + "), caches that do not apply the most restrictive one may store the response", true
+}
+
+// AnalyzeDuplicates inspects every instance of every header and reports the
+// ones sent more than once, plus CSP policies that combine into one.
+func AnalyzeDuplicates(resp *http.Response) []DuplicateFinding {
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out []DuplicateFinding
+	for _, name := range names {
+		vals := resp.Header.Values(name)
+		if name == "Content-Security-Policy" {
+			if f, ok := combinedCSP(vals); ok {
+				out = append(out, f)
+			}
+			continue
+		}
+		if len(vals) < 2 || name == "Set-Cookie" {
+			continue
+		}
+
+		distinct := map[string]bool{}
+		for _, v := range vals {
+			distinct[strings.ToLower(strings.TrimSpace(v))] = true
+		}
+		f := DuplicateFinding{Header: name, Values: vals, Kind: "duplicate"}
+		if len(distinct) > 1 {
+			f.Kind = "conflict"
+		}
+
+		msg, broken := invalidOnRepeat[name]
+		semantics, single := singletons[name]
+		switch {
+		case broken:
+			f.Severity, f.Message = "medium", "sent more than once, "+msg
+		case single && f.Kind == "conflict":
+			f.Severity, f.Message = "medium", "conflicting values, "+semantics
+			if name == "X-Frame-Options" {
+				f.Severity = "low"
+			}
+		case single:
+			f.Severity, f.Message = "low", "sent more than once with the same value"
+		case repeatable[name] && f.Kind == "conflict":
+			conflicts, ok := listConflicts[name]
+			if !ok {
+				continue
+			}
+			if f.Severity, f.Message, ok = conflicts(vals); !ok {
+				continue
+			}
+		case repeatable[name]:
+			f.Severity, f.Message = "info", "identical instances, one is enough"
+		case f.Kind == "conflict":
+			f.Severity, f.Message = "low", "conflicting values, which one applies depends on the client"
+		default:
+			f.Severity, f.Message = "info", "sent more than once with the same value"
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// combinedCSP reports when more than one enforced policy is delivered, in
+// separate headers or comma-separated in one. Each policy applies
+// independently, so a resource loads only if every policy allows it.
+func combinedCSP(vals []string) (DuplicateFinding, bool) {
+	var raw []string
+	for _, v := range vals {
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				raw = append(raw, p)
+			}
+		}
+	}
+	if len(raw) < 2 {
+		return DuplicateFinding{}, false
+	}
+	f := DuplicateFinding{Header: "Content-Security-Policy", Kind: "combined", Values: vals}
+
+	policies := make([]CSP, len(raw))
+	for i, p := range raw {
+		policies[i] = ParseCSP(p)
+	}
+	seen := map[string]bool{}
+	var differ []string
+	for _, p := range policies {
+		for _, d := range p.Order {
+			if seen[d] {
+				continue
+			}
+			seen[d] = true
+			first, _ := policies[0].Effective(d)
+			for _, q := range policies[1:] {
+				other, _ := q.Effective(d)
+				if strings.Join(first, " ") != strings.Join(other, " ") {
+					differ = append(differ, d)
+					break
+				}
+			}
+		}
+	}
+
+	if len(differ) == 0 {
+		f.Kind = "duplicate"
+		f.Severity, f.Message = "info", "identical policies, one is enough"
+		return f, true
+	}
+	f.Severity = "low"
+	f.Message = fmt.Sprintf("%d policies are enforced together and a resource must be allowed by all of them, so the effective policy is stricter than each one; they differ on %s",
+		len(raw), strings.Join(differ, ", "))
+	return f, true
+}
+
+func duplicatesCLI(findings []DuplicateFinding) {
+	section("Duplicate and Conflicting Headers")
+	if len(findings) == 0 {
+		fmt.Printf(" %s %s None found\n\n", "└─", green("["+tick+"]"))
+		return
+	}
+	for idx, f := range findings {
+		last := idx == len(findings)-1
+		branch, vert := "├─", "│"
+		if last {
+			branch, vert = "└─", " "
+		}
+		icon := green("[" + tick + "]")
+		switch f.Severity {
+		case "low":
+			icon = yellow("[" + warn + "]")
+		case "medium", "high":
+			icon = red("[" + cross + "]")
+		}
+		fmt.Printf(" %s %s %s (%s)\n", branch, icon, f.Header, f.Kind)
+		if ShowRecommendedDetails {
+			fmt.Printf(" %s  → [%s] %s\n", vert, f.Severity, f.Message)
+		}
+		for _, v := range f.Values {
+			fmt.Printf(" %s    %s\n", vert, v)
+		}
+		if !last {
+			fmt.Println(" │")
+		}
+	}
+	fmt.Println()
+}
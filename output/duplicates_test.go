@@ -0,0 +1,23 @@
+package output
+
+import "testing"
+
+func TestCacheControlConflicts(t *testing.T) {
+	tests := []struct {
+		vals     []string
+		severity string
+	}{
+		{[]string{"no-store", "public, max-age=3600"}, "medium"},
+		{[]string{"private", "s-maxage=60"}, "medium"},
+		{[]string{"max-age=0", "max-age=3600"}, "low"},
+		{[]string{`max-age="60"`, "MAX-AGE=60"}, ""},
+		{[]string{"no-store", "max-age=0"}, ""},
+		{[]string{"no-cache", "must-revalidate"}, ""},
+	}
+	for _, tt := range tests {
+		severity, _, ok := cacheControlConflicts(tt.vals)
+		if ok != (tt.severity != "") || severity != tt.severity {
+			t.Errorf("cacheControlConflicts(%q) = %q, %v, want %q", tt.vals, severity, ok, tt.severity)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package output
+
+import "fmt"
+
+// EdgeReport lists the CDNs and WAFs detected in front of the target. When
+// one is present, security headers may have to be set or preserved there
+// rather than on the origin.
+type EdgeReport struct {
+	CDN []Technology `json:"cdn,omitempty"`
+	WAF []Technology `json:"waf,omitempty"`
+}
+
+func edgeCLI(r *EdgeReport) {
+	section("CDN and WAF")
+	type row struct {
+		label string
+		tech  *Technology
+	}
+	var rows []row
+	for _, group := range []struct {
+		label string
+		techs []Technology
+	}{{"CDN", r.CDN}, {"WAF", r.WAF}} {
+		if len(group.techs) == 0 {
+			rows = append(rows, row{label: group.label})
+		}
+		for i := range group.techs {
+			rows = append(rows, row{group.label, &group.techs[i]})
+		}
+	}
+
+	for idx, rw := range rows {
+		last := idx == len(rows)-1
+		branch, vert := "├─", "│"
+		if last {
+			branch, vert = "└─", " "
+		}
+		if rw.tech == nil {
+			fmt.Printf(" %s %s: none detected\n", branch, rw.label)
+		} else {
+			fmt.Printf(" %s %s: %s (%d%%)\n", branch, rw.label, rw.tech.Name, rw.tech.Confidence)
+			if ShowRecommendedDetails {
+				for _, e := range rw.tech.Evidence {
+					fmt.Printf(" %s  → %s\n", vert, e)
+				}
+			}
+		}
+		if !last {
+			fmt.Println(" │")
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,127 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ETagInfo is what a server-generated ETag reveals about the file behind it.
+type ETagInfo struct {
+	Format   string     `json:"format"`
+	Inode    *uint64    `json:"inode,omitempty"`
+	Size     *uint64    `json:"size,omitempty"`
+	Modified *time.Time `json:"modified,omitempty"`
+}
+
+func (e ETagInfo) String() string {
+	var parts []string
+	if e.Inode != nil {
+		parts = append(parts, fmt.Sprintf("inode %d", *e.Inode))
+	}
+	if e.Size != nil {
+		parts = append(parts, fmt.Sprintf("size %d bytes", *e.Size))
+	}
+	if e.Modified != nil {
+		parts = append(parts, "modified "+e.Modified.UTC().Format(time.RFC3339))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// plausible reports whether t is a file modification time a server could
+// report, which rules out hashes that happen to decode.
+func plausible(t time.Time) bool {
+	return t.Year() >= 1995 && t.Before(time.Now().AddDate(1, 0, 0))
+}
+
+func parseHex(parts ...string) ([]uint64, bool) {
+	out := make([]uint64, len(parts))
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 16, 64)
+		if err != nil || p == "" {
+			return nil, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
+
+// DecodeETag recognises the ETag formats of common servers:
+//
+//	apache  "inode-size-mtime" or "size-mtime", hex, mtime in microseconds
+//	nginx   "mtime-size", hex, mtime in seconds
+//	iis     "filetime:change", hex FILETIME
+//	tomcat  W/"size-mtime", decimal, mtime in milliseconds
+//
+// Compression suffixes added by Apache (-gzip, -br) are ignored.
+func DecodeETag(raw string) (ETagInfo, bool) {
+	tag := strings.TrimPrefix(strings.TrimSpace(raw), "W/")
+	tag = strings.Trim(tag, `"`)
+	for _, suffix := range []string{"-gzip", "-br", "-deflate", ";gzip"} {
+		tag = strings.TrimSuffix(tag, suffix)
+	}
+
+	if ft, change, ok := strings.Cut(tag, ":"); ok {
+		n, ok := parseHex(ft, change)
+		if !ok || len(ft) < 14 {
+			return ETagInfo{}, false
+		}
+		// FILETIME counts 100ns intervals since 1601-01-01.
+		const epochDiff = 116444736000000000
+		if n[0] < epochDiff {
+			return ETagInfo{}, false
+		}
+		t := time.Unix(0, int64(n[0]-epochDiff)*100)
+		if !plausible(t) {
+			return ETagInfo{}, false
+		}
+		return ETagInfo{Format: "iis", Modified: &t}, true
+	}
+
+	parts := strings.Split(tag, "-")
+	if len(parts) == 2 && strings.HasPrefix(strings.TrimSpace(raw), "W/") {
+		size, err1 := strconv.ParseUint(parts[0], 10, 64)
+		ms, err2 := strconv.ParseInt(parts[1], 10, 64)
+		if t := time.UnixMilli(ms); err1 == nil && err2 == nil && len(parts[1]) == 13 && plausible(t) {
+			return ETagInfo{Format: "tomcat", Size: &size, Modified: &t}, true
+		}
+	}
+
+	n, ok := parseHex(parts...)
+	if !ok {
+		return ETagInfo{}, false
+	}
+	switch len(parts) {
+	case 3:
+		if t := time.UnixMicro(int64(n[2])); plausible(t) {
+			return ETagInfo{Format: "apache", Inode: &n[0], Size: &n[1], Modified: &t}, true
+		}
+	case 2:
+		if t := time.UnixMicro(int64(n[1])); len(parts[1]) >= 12 && plausible(t) {
+			return ETagInfo{Format: "apache", Size: &n[0], Modified: &t}, true
+		}
+		if t := time.Unix(int64(n[0]), 0); len(parts[0]) == 8 && plausible(t) {
+			return ETagInfo{Format: "nginx", Size: &n[1], Modified: &t}, true
+		}
+	}
+	return ETagInfo{}, false
+}
+
+// etagFinding reports an ETag that decodes to file metadata. Inode numbers
+// help fingerprint backends behind a load balancer and are rated higher than
+// size and modification time alone.
+func etagFinding(values []string) (LeakFinding, bool) {
+	for _, v := range values {
+		info, ok := DecodeETag(v)
+		if !ok {
+			continue
+		}
+		lf := LeakFinding{Header: "Etag", Value: strings.TrimSpace(v), Rule: "etag/" + info.Format, Category: "file-metadata", Severity: "info", ETag: &info}
+		if info.Inode != nil {
+			lf.Severity = "low"
+		}
+		return lf, true
+	}
+	return LeakFinding{}, false
+}
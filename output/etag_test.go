@@ -0,0 +1,86 @@
+package output
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecodeETag(t *testing.T) {
+	modified := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	u64 := func(n uint64) *uint64 { return &n }
+	tests := []struct {
+		in     string
+		format string
+		inode  *uint64
+		size   *uint64
+	}{
+		{`"2a3b-1f4-5b7cb6be58000"`, "apache", u64(0x2a3b), u64(500)},
+		{`W/"2a3b-1f4-5b7cb6be58000"`, "apache", u64(0x2a3b), u64(500)},
+		{`"2a3b-1f4-5b7cb6be58000-gzip"`, "apache", u64(0x2a3b), u64(500)},
+		{`"1f4-5b7cb6be58000-br"`, "apache", nil, u64(500)},
+		{`"5fee6600-1f4"`, "nginx", nil, u64(500)},
+		{` "5fee6600-1f4" `, "nginx", nil, u64(500)},
+		{`"1d6dfd10c358000:0"`, "iis", nil, nil},
+		{`W/"500-1609459200000"`, "tomcat", nil, u64(500)},
+	}
+	for _, tt := range tests {
+		info, ok := DecodeETag(tt.in)
+		if !ok {
+			t.Errorf("DecodeETag(%s) failed, want %s", tt.in, tt.format)
+			continue
+		}
+		if info.Format != tt.format {
+			t.Errorf("DecodeETag(%s).Format = %s, want %s", tt.in, info.Format, tt.format)
+		}
+		if info.Modified == nil || !info.Modified.Equal(modified) {
+			t.Errorf("DecodeETag(%s).Modified = %v, want %v", tt.in, info.Modified, modified)
+		}
+		for _, f := range []struct {
+			name      string
+			got, want *uint64
+		}{{"Inode", info.Inode, tt.inode}, {"Size", info.Size, tt.size}} {
+			if (f.got == nil) != (f.want == nil) || (f.got != nil && *f.got != *f.want) {
+				t.Errorf("DecodeETag(%s).%s = %v, want %v", tt.in, f.name, f.got, f.want)
+			}
+		}
+	}
+}
+
+func TestDecodeETagRejects(t *testing.T) {
+	for _, in := range []string{
+		``,
+		`""`,
+		`"abc"`,
+		`W/"0815"`,
+		`"33a64df551425fcc55e4d42a148795d9f25f89d4"`,
+		`"686897696a7c876b7e"`,
+		`"1-2"`,
+		`"1-2-3"`,
+		`"ffffffff-1f4"`,
+		`"5fee6600-xyz"`,
+		`"2a3b--5b7cb6be58000"`,
+		`"1d6dfd10c358000:zz"`,
+		`"1d6d:0"`,
+		`"0000000000000000:0"`,
+		`W/"500-16094592000"`,
+		`"a-b-c-d"`,
+	} {
+		if info, ok := DecodeETag(in); ok {
+			t.Errorf("DecodeETag(%s) = %+v, want no match", in, info)
+		}
+	}
+}
+
+func TestETagFinding(t *testing.T) {
+	lf, ok := etagFinding([]string{`"abc"`, `"2a3b-1f4-5b7cb6be58000"`})
+	if !ok || lf.Rule != "etag/apache" || lf.Severity != "low" {
+		t.Errorf("etagFinding with an inode = %+v, want etag/apache rated low", lf)
+	}
+	lf, ok = etagFinding([]string{`"5fee6600-1f4"`})
+	if !ok || lf.Severity != "info" {
+		t.Errorf("etagFinding without an inode = %+v, want info", lf)
+	}
+	if _, ok := etagFinding([]string{`"abc"`}); ok {
+		t.Error("etagFinding of an opaque ETag reported a finding")
+	}
+}
@@ -0,0 +1,169 @@
+package output
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// FramingReport is the clickjacking verdict for a response. Verdict is
+// protected (framing denied or same-origin only), restricted (explicit
+// origins may frame the page) or vulnerable.
+type FramingReport struct {
+	Verdict        string   `json:"verdict"`
+	EnforcedBy     string   `json:"enforced_by,omitempty"`
+	FrameAncestors string   `json:"frame_ancestors,omitempty"`
+	XFrameOptions  []string `json:"x_frame_options,omitempty"`
+	Issues         []Issue  `json:"issues,omitempty"`
+}
+
+// enforcedPolicies returns every policy delivered in Content-Security-Policy
+// headers, including comma-separated policies within one header.
+func enforcedPolicies(h http.Header) []CSP {
+	var out []CSP
+	for _, v := range h.Values("Content-Security-Policy") {
+		for _, p := range strings.Split(v, ",") {
+			if strings.TrimSpace(p) != "" {
+				out = append(out, ParseCSP(p))
+			}
+		}
+	}
+	return out
+}
+
+// frameAncestorsVerdict grades a frame-ancestors source list.
+func frameAncestorsVerdict(sources []string) string {
+	s := classifySources(sources)
+	switch {
+	case len(s.wildcard)+len(s.httpsScheme)+len(s.httpScheme) > 0:
+		return "vulnerable"
+	case s.none || len(sources) == 0:
+		return "protected"
+	}
+	for _, src := range sources {
+		if !strings.EqualFold(src, "'self'") {
+			return "restricted"
+		}
+	}
+	return "protected"
+}
+
+// AnalyzeFraming combines X-Frame-Options and CSP frame-ancestors the way
+// browsers do: frame-ancestors, when present, makes X-Frame-Options ignored.
+func AnalyzeFraming(resp *http.Response) *FramingReport {
+	r := &FramingReport{}
+	add := func(sev, hdr, format string, a ...interface{}) {
+		r.Issues = append(r.Issues, Issue{Severity: sev, Directive: hdr, Message: fmt.Sprintf(format, a...)})
+	}
+
+	var xfo []string
+	for _, v := range resp.Header.Values("X-Frame-Options") {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				xfo = append(xfo, item)
+			}
+		}
+	}
+	r.XFrameOptions = xfo
+
+	// Every enforced policy applies, so the strictest frame-ancestors wins.
+	var ancestors []string
+	for _, p := range enforcedPolicies(resp.Header) {
+		if src, ok := p.Directives["frame-ancestors"]; ok {
+			ancestors = append(ancestors, strings.TrimSpace("frame-ancestors "+strings.Join(src, " ")))
+			v := frameAncestorsVerdict(src)
+			if r.Verdict == "" || framingRank[v] < framingRank[r.Verdict] {
+				r.Verdict = v
+			}
+		}
+	}
+	if len(ancestors) > 0 {
+		r.EnforcedBy = "frame-ancestors"
+		r.FrameAncestors = strings.Join(ancestors, "; ")
+		if len(xfo) > 0 {
+			add("info", "X-Frame-Options", "ignored by modern browsers because frame-ancestors is set")
+		} else {
+			add("info", "X-Frame-Options", "missing, legacy browsers without CSP2 support are unprotected")
+		}
+		if r.Verdict == "vulnerable" {
+			add("high", "frame-ancestors", "allows framing by any origin")
+		}
+		return r
+	}
+
+	if len(xfo) == 0 {
+		r.Verdict = "vulnerable"
+		add("high", "", "neither X-Frame-Options nor CSP frame-ancestors is set, the page can be framed by any origin")
+		return r
+	}
+
+	distinct := map[string]bool{}
+	for _, v := range xfo {
+		distinct[strings.ToLower(v)] = true
+	}
+	if len(distinct) > 1 {
+		// HTML: conflicting values block framing if any of them is a
+		// recognised keyword, and are ignored otherwise.
+		if distinct["deny"] || distinct["sameorigin"] || distinct["allowall"] {
+			r.Verdict, r.EnforcedBy = "protected", "X-Frame-Options"
+			add("low", "X-Frame-Options", "conflicting values %s, browsers block all framing", strings.Join(xfo, ", "))
+		} else {
+			r.Verdict = "vulnerable"
+			add("high", "X-Frame-Options", "conflicting invalid values %s are ignored", strings.Join(xfo, ", "))
+		}
+		return r
+	}
+
+	switch v := strings.ToLower(xfo[0]); {
+	case v == "deny" || v == "sameorigin":
+		r.Verdict, r.EnforcedBy = "protected", "X-Frame-Options"
+		add("info", "frame-ancestors", "missing, prefer CSP frame-ancestors which supersedes X-Frame-Options")
+	case strings.HasPrefix(v, "allow-from"):
+		r.Verdict = "vulnerable"
+		add("high", "X-Frame-Options", "ALLOW-FROM is not supported by modern browsers, the header is ignored (use frame-ancestors)")
+	case v == "allowall":
+		r.Verdict = "vulnerable"
+		add("high", "X-Frame-Options", "ALLOWALL explicitly permits framing by any origin")
+	default:
+		r.Verdict = "vulnerable"
+		add("high", "X-Frame-Options", "invalid value %q, the header is ignored", xfo[0])
+	}
+	return r
+}
+
+var framingRank = map[string]int{"protected": 0, "restricted": 1, "vulnerable": 2}
+
+func framingCLI(r *FramingReport) {
+	section("Clickjacking Protection")
+
+	icon := green("[" + tick + "]")
+	switch r.Verdict {
+	case "restricted":
+		icon = yellow("[" + warn + "]")
+	case "vulnerable":
+		icon = red("[" + cross + "]")
+	}
+	verdict := strings.ToUpper(r.Verdict)
+	if r.EnforcedBy != "" {
+		verdict += " (enforced by " + r.EnforcedBy + ")"
+	}
+
+	lines := []string{}
+	if r.FrameAncestors != "" {
+		lines = append(lines, "Content-Security-Policy: "+r.FrameAncestors)
+	}
+	if len(r.XFrameOptions) > 0 {
+		lines = append(lines, "X-Frame-Options: "+strings.Join(r.XFrameOptions, ", "))
+	}
+	if ShowRecommendedDetails {
+		for _, is := range r.Issues {
+			lines = append(lines, issueLine(is))
+		}
+	}
+
+	fmt.Printf(" └─ %s %s\n", icon, verdict)
+	for _, l := range lines {
+		fmt.Printf("    → %s\n", l)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFrameAncestorsVerdict(t *testing.T) {
+	tests := []struct {
+		sources string
+		want    string
+	}{
+		{"", "protected"},
+		{"'none'", "protected"},
+		{"'self'", "protected"},
+		{"'SELF'", "protected"},
+		{"'self' https://partner.example", "restricted"},
+		{"partner.example", "restricted"},
+		{"*", "vulnerable"},
+		{"'self' https:", "vulnerable"},
+		{"http:", "vulnerable"},
+	}
+	for _, tt := range tests {
+		if got := frameAncestorsVerdict(strings.Fields(tt.sources)); got != tt.want {
+			t.Errorf("frameAncestorsVerdict(%q) = %s, want %s", tt.sources, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeFraming(t *testing.T) {
+	tests := []struct {
+		headers    []string
+		verdict    string
+		enforcedBy string
+		issues     []string
+	}{
+		{nil, "vulnerable", "", []string{"high "}},
+		{[]string{"X-Frame-Options: DENY"}, "protected", "X-Frame-Options", []string{"info frame-ancestors"}},
+		{[]string{"X-Frame-Options: sameorigin"}, "protected", "X-Frame-Options", []string{"info frame-ancestors"}},
+		{[]string{"X-Frame-Options: ALLOW-FROM https://a.example"}, "vulnerable", "", []string{"high X-Frame-Options"}},
+		{[]string{"X-Frame-Options: ALLOWALL"}, "vulnerable", "", []string{"high X-Frame-Options"}},
+		{[]string{"X-Frame-Options: nope"}, "vulnerable", "", []string{"high X-Frame-Options"}},
+		// Several values: framing is blocked if any is a keyword, even
+		// ALLOWALL, and the header is ignored otherwise.
+		{[]string{"X-Frame-Options: DENY", "X-Frame-Options: deny"}, "protected", "X-Frame-Options", []string{"info frame-ancestors"}},
+		{[]string{"X-Frame-Options: SAMEORIGIN, DENY"}, "protected", "X-Frame-Options", []string{"low X-Frame-Options"}},
+		{[]string{"X-Frame-Options: ALLOWALL", "X-Frame-Options: SAMEORIGIN"}, "protected", "X-Frame-Options", []string{"low X-Frame-Options"}},
+		{[]string{"X-Frame-Options: foo, bar"}, "vulnerable", "", []string{"high X-Frame-Options"}},
+		// frame-ancestors makes X-Frame-Options ignored.
+		{[]string{"Content-Security-Policy: frame-ancestors 'self'"}, "protected", "frame-ancestors", []string{"info X-Frame-Options"}},
+		{[]string{"Content-Security-Policy: frame-ancestors *", "X-Frame-Options: DENY"}, "vulnerable", "frame-ancestors", []string{"info X-Frame-Options", "high frame-ancestors"}},
+		{[]string{"Content-Security-Policy: frame-ancestors https://a.example", "X-Frame-Options: DENY"}, "restricted", "frame-ancestors", []string{"info X-Frame-Options"}},
+		{[]string{"Content-Security-Policy: default-src 'none'", "X-Frame-Options: DENY"}, "protected", "X-Frame-Options", []string{"info frame-ancestors"}},
+		// The strictest of several enforced policies wins.
+		{[]string{"Content-Security-Policy: frame-ancestors *, frame-ancestors 'none'"}, "protected", "frame-ancestors", []string{"info X-Frame-Options"}},
+		{[]string{"Content-Security-Policy: frame-ancestors *", "Content-Security-Policy: frame-ancestors https://a.example"}, "restricted", "frame-ancestors", []string{"info X-Frame-Options"}},
+		{[]string{"Content-Security-Policy-Report-Only: frame-ancestors 'none'"}, "vulnerable", "", []string{"high "}},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		for _, h := range tt.headers {
+			name, value, _ := strings.Cut(h, ": ")
+			resp.Header.Add(name, value)
+		}
+		r := AnalyzeFraming(resp)
+		var got []string
+		for _, is := range r.Issues {
+			got = append(got, is.Severity+" "+is.Directive)
+		}
+		if r.Verdict != tt.verdict || r.EnforcedBy != tt.enforcedBy || !reflect.DeepEqual(got, tt.issues) {
+			t.Errorf("AnalyzeFraming(%q) = %s by %q %q, want %s by %q %q", tt.headers, r.Verdict, r.EnforcedBy, got, tt.verdict, tt.enforcedBy, tt.issues)
+		}
+	}
+}
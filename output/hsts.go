@@ -0,0 +1,126 @@
+package output
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	hstsOneDay  = 86400
+	hstsOneYear = 31536000
+)
+
+// HSTS is a parsed Strict-Transport-Security header (RFC 6797 §6.1).
+// Invalid is set when the value breaks a rule that makes user agents ignore
+// the whole header, with the reasons listed in Issues.
+type HSTS struct {
+	MaxAge            int64
+	HasMaxAge         bool
+	IncludeSubDomains bool
+	Preload           bool
+	Invalid           bool
+	Issues            []Issue
+}
+
+// ParseHSTS parses a Strict-Transport-Security value. Directive names are
+// case-insensitive and max-age may be a quoted-string, as the RFC allows.
+func ParseHSTS(raw string) HSTS {
+	var h HSTS
+	seen := map[string]bool{}
+	invalid := func(dir, format string, a ...interface{}) {
+		h.Invalid = true
+		h.Issues = append(h.Issues, Issue{Severity: "high", Directive: dir, Message: fmt.Sprintf(format, a...)})
+	}
+
+	for _, part := range strings.Split(raw, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, hasValue := strings.Cut(part, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+
+		if seen[name] {
+			invalid(name, "directive appears more than once, browsers ignore the header")
+			continue
+		}
+		seen[name] = true
+
+		switch name {
+		case "max-age":
+			if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+				value = value[1 : len(value)-1]
+			}
+			n, err := strconv.ParseInt(value, 10, 64)
+			if !hasValue || err != nil || n < 0 || strings.ContainsAny(value, "+-") {
+				invalid(name, "%q is not a valid delta-seconds value, browsers ignore the header", value)
+				continue
+			}
+			h.MaxAge, h.HasMaxAge = n, true
+		case "includesubdomains":
+			if hasValue {
+				invalid(name, "directive does not take a value, browsers ignore the header")
+				continue
+			}
+			h.IncludeSubDomains = true
+		case "preload":
+			h.Preload = true
+		default:
+			h.Issues = append(h.Issues, Issue{Severity: "low", Directive: name, Message: "unknown directive, ignored by browsers"})
+		}
+	}
+	if !h.HasMaxAge && !h.Invalid {
+		invalid("max-age", "missing, browsers ignore the header")
+	}
+	return h
+}
+
+// AnalyzeHSTS grades a parsed policy. scheme is the scheme of the request
+// the header was received on: browsers only honour HSTS over HTTPS.
+func AnalyzeHSTS(h HSTS, scheme string) []Issue {
+	issues := append([]Issue(nil), h.Issues...)
+	add := func(sev, dir, msg string) {
+		issues = append(issues, Issue{Severity: sev, Directive: dir, Message: msg})
+	}
+
+	if strings.EqualFold(scheme, "http") {
+		add("high", "", "sent over plain http://, browsers ignore HSTS received without TLS")
+	}
+	if h.Invalid {
+		return issues
+	}
+
+	switch {
+	case h.MaxAge == 0:
+		add("high", "max-age", "max-age=0 instructs browsers to remove the HSTS policy")
+	case h.MaxAge < hstsOneDay:
+		add("high", "max-age", fmt.Sprintf("%d seconds is too short to offer meaningful protection", h.MaxAge))
+	case h.MaxAge < hstsOneYear:
+		add("medium", "max-age", fmt.Sprintf("%d seconds is below the recommended one year (%d)", h.MaxAge, hstsOneYear))
+	}
+	if !h.IncludeSubDomains {
+		add("medium", "includeSubDomains", "missing, subdomains can still be reached over HTTP")
+	}
+	if h.Preload && (h.MaxAge < hstsOneYear || !h.IncludeSubDomains) {
+		add("low", "preload", "set, but the preload list also requires max-age of at least one year and includeSubDomains")
+	} else if !h.Preload {
+		add("info", "preload", "not set, the domain can't be submitted to the HSTS preload list")
+	}
+	return issues
+}
+
+func checkHSTS(f *RecFinding, val string, resp *http.Response, _ *Evidence) {
+	scheme := ""
+	if resp.Request != nil && resp.Request.URL != nil {
+		scheme = resp.Request.URL.Scheme
+	}
+	f.Issues = AnalyzeHSTS(ParseHSTS(val), scheme)
+	if hasSerious(f.Issues) {
+		f.Status = "weak"
+	} else {
+		f.Status = "ok"
+	}
+}
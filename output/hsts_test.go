@@ -0,0 +1,76 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHSTS(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   HSTS
+		issues []string
+	}{
+		{"max-age=31536000; includeSubDomains; preload", HSTS{MaxAge: 31536000, HasMaxAge: true, IncludeSubDomains: true, Preload: true}, nil},
+		{`MAX-AGE="600";INCLUDESUBDOMAINS`, HSTS{MaxAge: 600, HasMaxAge: true, IncludeSubDomains: true}, nil},
+		{" max-age = 0 ;; ", HSTS{HasMaxAge: true}, nil},
+		{"max-age=60; report-uri=x", HSTS{MaxAge: 60, HasMaxAge: true}, []string{"[low] report-uri: unknown directive, ignored by browsers"}},
+		// Any of these makes browsers ignore the whole header.
+		{"includeSubDomains", HSTS{IncludeSubDomains: true, Invalid: true}, []string{"[high] max-age: missing, browsers ignore the header"}},
+		{"max-age=60; max-age=120", HSTS{MaxAge: 60, HasMaxAge: true, Invalid: true}, []string{"[high] max-age: directive appears more than once, browsers ignore the header"}},
+		{"max-age", HSTS{Invalid: true}, []string{`[high] max-age: "" is not a valid delta-seconds value, browsers ignore the header`}},
+		{"max-age=-1", HSTS{Invalid: true}, []string{`[high] max-age: "-1" is not a valid delta-seconds value, browsers ignore the header`}},
+		{"max-age=+60", HSTS{Invalid: true}, []string{`[high] max-age: "+60" is not a valid delta-seconds value, browsers ignore the header`}},
+		{"max-age=1y", HSTS{Invalid: true}, []string{`[high] max-age: "1y" is not a valid delta-seconds value, browsers ignore the header`}},
+		{"max-age=60; includeSubDomains=1", HSTS{MaxAge: 60, HasMaxAge: true, Invalid: true}, []string{"[high] includesubdomains: directive does not take a value, browsers ignore the header"}},
+	}
+	for _, tt := range tests {
+		got := ParseHSTS(tt.in)
+		var issues []string
+		for _, is := range got.Issues {
+			issues = append(issues, issueLine(is))
+		}
+		got.Issues = nil
+		if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(issues, tt.issues) {
+			t.Errorf("ParseHSTS(%q) = %+v %q, want %+v %q", tt.in, got, issues, tt.want, tt.issues)
+		}
+	}
+}
+
+func TestAnalyzeHSTS(t *testing.T) {
+	tests := []struct {
+		in, scheme string
+		want       []string
+	}{
+		{"max-age=31536000; includeSubDomains; preload", "https", nil},
+		{"max-age=31536000; includeSubDomains", "https", []string{"[info] preload: not set, the domain can't be submitted to the HSTS preload list"}},
+		{"max-age=31536000; includeSubDomains; preload", "http", []string{"[high] sent over plain http://, browsers ignore HSTS received without TLS"}},
+		{"max-age=0; includeSubDomains; preload", "https", []string{
+			"[high] max-age: max-age=0 instructs browsers to remove the HSTS policy",
+			"[low] preload: set, but the preload list also requires max-age of at least one year and includeSubDomains",
+		}},
+		{"max-age=3600; includeSubDomains; preload", "https", []string{
+			"[high] max-age: 3600 seconds is too short to offer meaningful protection",
+			"[low] preload: set, but the preload list also requires max-age of at least one year and includeSubDomains",
+		}},
+		{"max-age=86400; preload", "https", []string{
+			"[medium] max-age: 86400 seconds is below the recommended one year (31536000)",
+			"[medium] includeSubDomains: missing, subdomains can still be reached over HTTP",
+			"[low] preload: set, but the preload list also requires max-age of at least one year and includeSubDomains",
+		}},
+		// An invalid header is reported with its parse errors only.
+		{"max-age=1; max-age=2", "http", []string{
+			"[high] max-age: directive appears more than once, browsers ignore the header",
+			"[high] sent over plain http://, browsers ignore HSTS received without TLS",
+		}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, is := range AnalyzeHSTS(ParseHSTS(tt.in), tt.scheme) {
+			got = append(got, issueLine(is))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AnalyzeHSTS(%q, %s) = %q, want %q", tt.in, tt.scheme, got, tt.want)
+		}
+	}
+}
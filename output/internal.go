@@ -0,0 +1,140 @@
+package output
+
+import (
+	"net/netip"
+	"regexp"
+	"strings"
+)
+
+// DefaultInternalDomains are the suffixes of hostnames that only resolve
+// inside a private network.
+var DefaultInternalDomains = []string{"local", "localdomain", "internal", "intranet", "corp", "lan", "home.arpa"}
+
+// internalDomains are the suffixes AnalyzeLeaks reports, without leading dots.
+var internalDomains = DefaultInternalDomains
+
+// SetInternalDomains replaces the internal domain suffixes, e.g. with the
+// organisation's own corp.example.com.
+func SetInternalDomains(suffixes []string) {
+	internalDomains = nil
+	for _, s := range suffixes {
+		if s = strings.Trim(strings.ToLower(strings.TrimSpace(s)), "."); s != "" {
+			internalDomains = append(internalDomains, s)
+		}
+	}
+}
+
+// internalPrefixes are the private and reserved ranges worth reporting, with
+// the rule name they are reported under.
+var internalPrefixes = []struct {
+	prefix netip.Prefix
+	rule   string
+}{
+	{netip.MustParsePrefix("10.0.0.0/8"), "private-ipv4"},
+	{netip.MustParsePrefix("172.16.0.0/12"), "private-ipv4"},
+	{netip.MustParsePrefix("192.168.0.0/16"), "private-ipv4"},
+	{netip.MustParsePrefix("100.64.0.0/10"), "shared-address-space"},
+	{netip.MustParsePrefix("127.0.0.0/8"), "loopback"},
+	{netip.MustParsePrefix("169.254.0.0/16"), "link-local"},
+	{netip.MustParsePrefix("198.18.0.0/15"), "reserved"},
+	{netip.MustParsePrefix("240.0.0.0/4"), "reserved"},
+	{netip.MustParsePrefix("::1/128"), "loopback"},
+	{netip.MustParsePrefix("fc00::/7"), "unique-local-ipv6"},
+	{netip.MustParsePrefix("fe80::/10"), "link-local"},
+}
+
+var (
+	ipv4Candidate = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b`)
+	ipv6Candidate = regexp.MustCompile(`(?i)[0-9a-f]{0,4}(?::[0-9a-f]{0,4}){2,7}(?:%[\w.-]+)?`)
+	hostCandidate = regexp.MustCompile(`(?i)\b[a-z0-9](?:[a-z0-9-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]*[a-z0-9])?)+\b`)
+)
+
+// versionLike reports whether the address at s[start:end] is more likely
+// part of a version than a host: Apache/10.0.0.1, v10.0.0.1 or 1.2.3.4.5.
+func versionLike(s string, start, end int) bool {
+	if start > 0 {
+		prev := s[start-1]
+		if prev == '/' && (start < 2 || s[start-2] != '/') {
+			return true
+		}
+		if prev == '.' || prev == 'v' || prev == 'V' {
+			return true
+		}
+	}
+	return end+1 < len(s) && s[end] == '.' && s[end+1] >= '0' && s[end+1] <= '9'
+}
+
+// internalRule returns the rule name of addr when it is private or reserved.
+func internalRule(addr netip.Addr) (string, bool) {
+	addr = addr.Unmap().WithZone("")
+	for _, p := range internalPrefixes {
+		if p.prefix.Contains(addr) {
+			return p.rule, true
+		}
+	}
+	return "", false
+}
+
+// internalDomain reports whether host ends with one of the internal suffixes.
+func internalDomain(host string) bool {
+	host = strings.ToLower(host)
+	for _, s := range internalDomains {
+		if host == s || strings.HasSuffix(host, "."+s) {
+			return true
+		}
+	}
+	return false
+}
+
+// internalFindings reports the private addresses and internal hostnames in
+// the values of a header.
+func internalFindings(name string, values []string) []LeakFinding {
+	var out []LeakFinding
+	seen := map[string]bool{}
+	add := func(val, match, category, rule string) {
+		if seen[match] {
+			return
+		}
+		seen[match] = true
+		out = append(out, LeakFinding{
+			Header:   name,
+			Value:    val,
+			Rule:     "internal/" + rule,
+			Category: category,
+			Match:    match,
+			Severity: "low",
+		})
+	}
+
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		for _, loc := range ipv4Candidate.FindAllStringIndex(v, -1) {
+			if versionLike(v, loc[0], loc[1]) {
+				continue
+			}
+			if addr, err := netip.ParseAddr(v[loc[0]:loc[1]]); err == nil {
+				if rule, ok := internalRule(addr); ok {
+					add(v, addr.String(), "internal-ip", rule)
+				}
+			}
+		}
+		for _, m := range ipv6Candidate.FindAllString(v, -1) {
+			addr, err := netip.ParseAddr(m)
+			if err != nil || !addr.Is6() || addr.Is4In6() {
+				continue
+			}
+			if rule, ok := internalRule(addr); ok {
+				add(v, addr.String(), "internal-ip", rule)
+			}
+		}
+		if len(internalDomains) == 0 {
+			continue
+		}
+		for _, m := range hostCandidate.FindAllString(v, -1) {
+			if internalDomain(m) {
+				add(v, strings.ToLower(m), "internal-host", "internal-domain")
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,167 @@
+package output
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IsolationReport evaluates COOP, COEP and CORP together. CrossOriginIsolated
+// tells whether window.crossOriginIsolated would be true for the page.
+type IsolationReport struct {
+	COOP                string  `json:"coop,omitempty"`
+	COEP                string  `json:"coep,omitempty"`
+	CORP                string  `json:"corp,omitempty"`
+	CrossOriginIsolated bool    `json:"cross_origin_isolated"`
+	Issues              []Issue `json:"issues,omitempty"`
+}
+
+var isolationValues = map[string][]string{
+	"Cross-Origin-Opener-Policy":   {"unsafe-none", "same-origin-allow-popups", "same-origin", "noopener-allow-popups"},
+	"Cross-Origin-Embedder-Policy": {"unsafe-none", "require-corp", "credentialless"},
+	"Cross-Origin-Resource-Policy": {"same-site", "same-origin", "cross-origin"},
+}
+
+// isolationValue returns the keyword a browser would use for hdr, or "" when
+// the header is absent. COOP and COEP are structured-field tokens with
+// optional parameters (report-to); CORP is a plain keyword.
+func isolationValue(resp *http.Response, hdr string) (value string, valid bool) {
+	raw := strings.TrimSpace(resp.Header.Get(hdr))
+	if raw == "" {
+		return "", true
+	}
+	if hdr != "Cross-Origin-Resource-Policy" {
+		it, err := ParseSFItem(raw)
+		if err != nil {
+			return raw, false
+		}
+		tok, ok := it.Value.(SFToken)
+		if !ok {
+			return raw, false
+		}
+		raw = string(tok)
+	}
+	for _, v := range isolationValues[hdr] {
+		if raw == v {
+			return raw, true
+		}
+	}
+	return raw, false
+}
+
+// AnalyzeIsolation reports whether the page is cross-origin isolated and
+// whether the COOP/COEP/CORP combination is coherent.
+func AnalyzeIsolation(resp *http.Response) *IsolationReport {
+	r := &IsolationReport{}
+	add := func(sev, hdr, format string, a ...interface{}) {
+		r.Issues = append(r.Issues, Issue{Severity: sev, Directive: hdr, Message: fmt.Sprintf(format, a...)})
+	}
+
+	var coopOK, coepOK, corpOK bool
+	r.COOP, coopOK = isolationValue(resp, "Cross-Origin-Opener-Policy")
+	r.COEP, coepOK = isolationValue(resp, "Cross-Origin-Embedder-Policy")
+	r.CORP, corpOK = isolationValue(resp, "Cross-Origin-Resource-Policy")
+	// Invalid values are reported and then treated as absent.
+	coop, coep, corp := r.COOP, r.COEP, r.CORP
+	for _, v := range []struct {
+		hdr string
+		ok  bool
+		val *string
+	}{
+		{"Cross-Origin-Opener-Policy", coopOK, &coop},
+		{"Cross-Origin-Embedder-Policy", coepOK, &coep},
+		{"Cross-Origin-Resource-Policy", corpOK, &corp},
+	} {
+		if !v.ok {
+			add("medium", v.hdr, "invalid value, browsers fall back to the default (expected one of %s)", strings.Join(isolationValues[v.hdr], ", "))
+			*v.val = ""
+		}
+	}
+
+	// Browsers only isolate secure contexts.
+	isolating := coop == "same-origin" && (coep == "require-corp" || coep == "credentialless")
+	secure := resp.Request != nil && resp.Request.URL != nil && trustworthy(resp.Request.URL)
+	r.CrossOriginIsolated = isolating && secure
+	if isolating && !secure {
+		add("medium", "Cross-Origin-Embedder-Policy", "ignored outside a secure context, the page must be served over HTTPS (or from localhost) to be cross-origin isolated")
+	}
+
+	switch coop {
+	case "", "unsafe-none":
+		add("medium", "Cross-Origin-Opener-Policy", "cross-origin windows keep a reference to this page (opener attacks, XS-Leaks)")
+	case "same-origin-allow-popups", "noopener-allow-popups":
+		add("info", "Cross-Origin-Opener-Policy", "%s protects against openers but does not enable cross-origin isolation", coop)
+	case "same-origin":
+		add("info", "Cross-Origin-Opener-Policy", "same-origin severs cross-origin popups, which breaks OAuth and payment flows relying on window.opener")
+	}
+
+	switch coep {
+	case "", "unsafe-none":
+		if coop == "same-origin" {
+			add("low", "Cross-Origin-Embedder-Policy", "not in effect, COOP same-origin alone does not make the page cross-origin isolated")
+		}
+	case "require-corp":
+		add("info", "Cross-Origin-Embedder-Policy", "require-corp blocks cross-origin subresources and iframes that lack CORP or CORS")
+	case "credentialless":
+		add("info", "Cross-Origin-Embedder-Policy", "credentialless loads cross-origin no-cors subresources without cookies")
+	}
+	if (coep == "require-corp" || coep == "credentialless") && coop != "same-origin" {
+		add("low", "Cross-Origin-Embedder-Policy", "has no isolation effect without Cross-Origin-Opener-Policy: same-origin")
+	}
+
+	switch corp {
+	case "":
+		add("low", "Cross-Origin-Resource-Policy", "not in effect, any origin can embed this response with no-cors requests")
+	case "cross-origin":
+		add("info", "Cross-Origin-Resource-Policy", "cross-origin lets any site embed this response, expected only for public assets")
+	case "same-origin", "same-site":
+		add("info", "Cross-Origin-Resource-Policy", "%s prevents other sites from embedding this response, including cross-origin isolated pages", corp)
+	}
+
+	for _, hdr := range []string{"Cross-Origin-Opener-Policy-Report-Only", "Cross-Origin-Embedder-Policy-Report-Only"} {
+		if resp.Header.Get(hdr) != "" {
+			add("info", hdr, "Report-Only variant present, its violations are only reported and not enforced")
+		}
+	}
+	return r
+}
+
+func isolationCLI(r *IsolationReport) {
+	section("Cross-Origin Isolation")
+
+	icon, verdict := yellow("["+warn+"]"), "NOT ISOLATED"
+	if r.CrossOriginIsolated {
+		icon, verdict = green("["+tick+"]"), "ISOLATED (crossOriginIsolated is true)"
+	}
+	fmt.Printf(" ├─ %s %s\n", icon, verdict)
+	fmt.Println(" │")
+
+	rows := [][2]string{
+		{"Cross-Origin-Opener-Policy", r.COOP},
+		{"Cross-Origin-Embedder-Policy", r.COEP},
+		{"Cross-Origin-Resource-Policy", r.CORP},
+	}
+	for idx, row := range rows {
+		last := idx == len(rows)-1
+		branch, vert := "├─", "│"
+		if last {
+			branch, vert = "└─", " "
+		}
+		val := row[1]
+		if val == "" {
+			val = "MISSING"
+		}
+		fmt.Printf(" %s %s: %s\n", branch, row[0], val)
+		if ShowRecommendedDetails {
+			for _, is := range r.Issues {
+				if strings.HasPrefix(is.Directive, row[0]) {
+					fmt.Printf(" %s  → [%s] %s\n", vert, is.Severity, is.Message)
+				}
+			}
+		}
+		if !last {
+			fmt.Println(" │")
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,63 @@
+package output
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeIsolation(t *testing.T) {
+	short := strings.NewReplacer("Cross-Origin-Opener-Policy", "COOP", "Cross-Origin-Embedder-Policy", "COEP", "Cross-Origin-Resource-Policy", "CORP")
+	tests := []struct {
+		url      string
+		headers  []string
+		isolated bool
+		issues   []string
+	}{
+		{"https://example.com/", nil, false, []string{"medium COOP", "low CORP"}},
+		{"https://example.com/", []string{"COOP: same-origin", "COEP: require-corp", "CORP: same-origin"}, true,
+			[]string{"info COOP", "info COEP", "info CORP"}},
+		{"https://example.com/", []string{"COOP: same-origin; report-to=\"coop\"", "COEP: credentialless"}, true,
+			[]string{"info COOP", "info COEP", "low CORP"}},
+		// Only secure contexts are isolated; localhost is one.
+		{"http://example.com/", []string{"COOP: same-origin", "COEP: require-corp"}, false,
+			[]string{"medium COEP", "info COOP", "info COEP", "low CORP"}},
+		{"http://192.0.2.2/", []string{"COOP: same-origin", "COEP: require-corp"}, false,
+			[]string{"medium COEP", "info COOP", "info COEP", "low CORP"}},
+		{"http://localhost:8080/", []string{"COOP: same-origin", "COEP: require-corp"}, true,
+			[]string{"info COOP", "info COEP", "low CORP"}},
+		{"http://127.0.0.1/", []string{"COOP: same-origin", "COEP: require-corp"}, true,
+			[]string{"info COOP", "info COEP", "low CORP"}},
+		// Half of the pair does not isolate.
+		{"https://example.com/", []string{"COOP: same-origin"}, false,
+			[]string{"info COOP", "low COEP", "low CORP"}},
+		{"https://example.com/", []string{"COOP: same-origin-allow-popups", "COEP: require-corp"}, false,
+			[]string{"info COOP", "info COEP", "low COEP", "low CORP"}},
+		// Invalid values are reported and treated as absent.
+		{"https://example.com/", []string{"COOP: \"same-origin\"", "COEP: require-corp", "CORP: Same-Origin"}, false,
+			[]string{"medium COOP", "medium CORP", "medium COOP", "info COEP", "low COEP", "low CORP"}},
+		{"https://example.com/", []string{"COOP: same-origin", "COEP: require_corp"}, false,
+			[]string{"medium COEP", "info COOP", "low COEP", "low CORP"}},
+		{"https://example.com/", []string{"COOP: noopener-allow-popups", "CORP: cross-origin", "COOP-Report-Only: same-origin"}, false,
+			[]string{"info COOP", "info CORP", "info COOP-Report-Only"}},
+	}
+	long := strings.NewReplacer("COOP", "Cross-Origin-Opener-Policy", "COEP", "Cross-Origin-Embedder-Policy", "CORP", "Cross-Origin-Resource-Policy")
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		resp := &http.Response{Header: http.Header{}, Request: &http.Request{URL: u}}
+		for _, h := range tt.headers {
+			name, value, _ := strings.Cut(long.Replace(h), ": ")
+			resp.Header.Add(name, value)
+		}
+		r := AnalyzeIsolation(resp)
+		var got []string
+		for _, is := range r.Issues {
+			got = append(got, is.Severity+" "+short.Replace(is.Directive))
+		}
+		if r.CrossOriginIsolated != tt.isolated || !reflect.DeepEqual(got, tt.issues) {
+			t.Errorf("AnalyzeIsolation(%s, %q) = %v %q, want %v %q", tt.url, tt.headers, r.CrossOriginIsolated, got, tt.isolated, tt.issues)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package output
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LeakRule flags a header whose name matches Header and, when Value is set,
+// whose value matches Value. Matches that also match Exclude are ignored and
+// headers listed in Skip are not checked.
+type LeakRule struct {
+	Name     string
+	Category string
+	Severity string
+	Header   *regexp.Regexp
+	Value    *regexp.Regexp
+	Exclude  *regexp.Regexp
+	Skip     map[string]bool
+}
+
+// LeakRuleSet is a named group of rules, such as the known leak headers.
+type LeakRuleSet struct {
+	Name  string
+	Rules []LeakRule
+}
+
+var severityRank = map[string]int{"info": 0, "low": 1, "medium": 2, "high": 3}
+
+// headerGlob matches header names case-insensitively against shell-style
+// patterns, where '*' is any run of characters and '?' a single one.
+func headerGlob(patterns ...string) *regexp.Regexp {
+	alts := make([]string, len(patterns))
+	for i, p := range patterns {
+		p = regexp.QuoteMeta(p)
+		p = strings.ReplaceAll(p, `\*`, ".*")
+		alts[i] = strings.ReplaceAll(p, `\?`, ".")
+	}
+	return regexp.MustCompile(`(?i)^(?:` + strings.Join(alts, "|") + `)$`)
+}
+
+// urlHeaders carry URLs or cookie attributes, whose paths and version-like
+// segments are not disclosures by themselves.
+var urlHeaders = map[string]bool{
+	"Location": true, "Content-Location": true, "Link": true, "Refresh": true, "Set-Cookie": true,
+	"Content-Security-Policy": true, "Content-Security-Policy-Report-Only": true,
+	"Report-To": true, "Reporting-Endpoints": true, "Nel": true,
+	"Access-Control-Allow-Origin": true, "Sourcemap": true, "X-Sourcemap": true,
+}
+
+// leakRuleSets are evaluated against every response header. The first set is
+// the historical list of known leak headers.
+var leakRuleSets = []LeakRuleSet{
+	{Name: "known", Rules: []LeakRule{
+		{Name: "leak-header", Category: "disclosure", Severity: "info", Header: headerGlob(leaks...)},
+	}},
+	{Name: "name", Rules: []LeakRule{
+		{Name: "symfony-profiler", Category: "debug", Severity: "medium", Header: headerGlob("X-Debug-Token", "X-Debug-Token-Link")},
+		{Name: "debug-header", Category: "debug", Severity: "low", Header: headerGlob("X-Debug*", "X-*-Debug*", "Debug-*", "X-ChromeLogger-Data", "X-ChromePhp-Data", "X-Clockwork-*", "X-Runtime", "X-Rack-Cache", "X-Execution-Time")},
+		{Name: "backend-header", Category: "infrastructure", Severity: "low", Header: headerGlob("X-Backend*", "X-Upstream*", "X-Forwarded-Server", "X-Origin-Server", "X-Real-Server", "X-Server-Name", "X-Hostname", "X-Host", "X-Node*", "X-Pod-*", "X-Instance-Id", "X-Served-From")},
+		{Name: "cloud-request-id", Category: "cloud", Severity: "info", Header: headerGlob("X-Amz-Request-Id", "X-Amz-Id-2", "X-Amzn-RequestId", "X-Amz-Apigw-Id", "X-Amzn-Remapped-*", "X-Ms-Request-Id", "X-Ms-Version", "X-Guploader-Uploadid", "X-Goog-*", "X-Azure-Ref")},
+		{Name: "tracing-header", Category: "tracing", Severity: "info", Header: headerGlob("Traceparent", "Tracestate", "X-Amzn-Trace-Id", "X-Cloud-Trace-Context", "Uber-Trace-Id", "X-Datadog-*", "X-Trace-Id")},
+	}},
+	{Name: "value", Rules: []LeakRule{
+		{Name: "stack-trace", Category: "stack-trace", Severity: "medium",
+			Value: regexp.MustCompile(`\bat [\w$.<>]+\([\w$.-]+\.(?:java|kt|scala|groovy):\d+\)|Traceback \(most recent call last\)|\bin /\S+\.php on line \d+|\bException in thread\b|\b(?:System|java|javax)\.[\w.]*(?:Exception|Error)\b|\bat [\w.]+\([^)]*\) in \S+:line \d+`)},
+		{Name: "file-path", Category: "file-path", Severity: "low", Skip: urlHeaders,
+			Value: regexp.MustCompile(`(?:^|[\s"'=:,(])(/(?:var|usr|home|opt|srv|etc|tmp|root|app|data|mnt)/[\w.~/-]+|[A-Za-z]:\\[\w\\.~ -]+)`)},
+		// Protocol versions, as in Via or Upgrade, are not software versions.
+		{Name: "version-string", Category: "version", Severity: "low", Skip: urlHeaders,
+			Value:   regexp.MustCompile(`\b[A-Za-z][\w.+-]*/v?\d+\.\d+(?:\.\d+)*\b`),
+			Exclude: regexp.MustCompile(`(?i)^(?:https?|h2c?|h3|spdy|quic)/`)},
+	}},
+}
+
+// apply returns the finding rule r produces on the values of header name.
+// Every matching value is kept, joined as a repeated header would be, with
+// the distinct matches in Match.
+func (r LeakRule) apply(name string, values []string) (LeakFinding, bool) {
+	if r.Skip[name] || (r.Header != nil && !r.Header.MatchString(name)) {
+		return LeakFinding{}, false
+	}
+	var vals, matches []string
+	seen := map[string]bool{}
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		if r.Value == nil {
+			vals = append(vals, v)
+			continue
+		}
+		match, ok := r.match(v)
+		if !ok {
+			continue
+		}
+		vals = append(vals, v)
+		if !seen[match] {
+			seen[match] = true
+			matches = append(matches, match)
+		}
+	}
+	if len(vals) == 0 {
+		return LeakFinding{}, false
+	}
+	return LeakFinding{
+		Header:   name,
+		Value:    strings.Join(vals, ", "),
+		Match:    strings.Join(matches, ", "),
+		Category: r.Category,
+		Severity: r.Severity,
+	}, true
+}
+
+// match returns the first match of r.Value in v that Exclude does not reject:
+// its last capture group, or the whole match when the group is empty.
+func (r LeakRule) match(v string) (string, bool) {
+	for _, m := range r.Value.FindAllStringSubmatch(v, -1) {
+		match := strings.TrimSpace(m[len(m)-1])
+		if match == "" {
+			match = strings.TrimSpace(m[0])
+		}
+		if r.Exclude == nil || !r.Exclude.MatchString(match) {
+			return match, true
+		}
+	}
+	return "", false
+}
+
+// AnalyzeLeaks runs the leak rule sets over every header in resp and returns
+// the most severe finding per header, followed by the secrets, decoded ETag
+// metadata, timing exposure, private addresses and internal hostnames it
+// carries. It needs the raw headers to find secrets, and redacts them in
+// every finding it returns.
+func AnalyzeLeaks(resp *http.Response) []LeakFinding {
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out []LeakFinding
+	for _, name := range names {
+		found, secrets := secretFindings(name, resp.Header.Values(name))
+		var best *LeakFinding
+		for _, set := range leakRuleSets {
+			for _, r := range set.Rules {
+				lf, ok := r.apply(name, resp.Header.Values(name))
+				if !ok {
+					continue
+				}
+				lf.Rule = set.Name + "/" + r.Name
+				analyzeLeak(&lf)
+				if best == nil || severityRank[lf.Severity] > severityRank[best.Severity] {
+					best = &lf
+				}
+			}
+		}
+		if best != nil {
+			best.Value = redactAll(best.Value, secrets)
+			best.Match = redactAll(best.Match, secrets)
+			for i := range best.Products {
+				p := &best.Products[i]
+				p.Name, p.Version, p.Comment = redactAll(p.Name, secrets), redactAll(p.Version, secrets), redactAll(p.Comment, secrets)
+			}
+			out = append(out, *best)
+		}
+		out = append(out, found...)
+		switch name {
+		case "Etag":
+			if lf, ok := etagFinding(resp.Header.Values(name)); ok {
+				out = append(out, lf)
+			}
+		case "Server-Timing":
+			if lf, ok := serverTimingFinding(resp.Header.Values(name), timingAllowsAll(resp.Header.Values("Timing-Allow-Origin"))); ok {
+				lf.Value = redactAll(lf.Value, secrets)
+				for i := range lf.Metrics {
+					lf.Metrics[i].Description = redactAll(lf.Metrics[i].Description, secrets)
+				}
+				out = append(out, lf)
+			}
+		case "Timing-Allow-Origin":
+			if lf, ok := timingAllowOriginFinding(resp.Header.Values(name)); ok {
+				out = append(out, lf)
+			}
+		}
+		for _, lf := range internalFindings(name, resp.Header.Values(name)) {
+			lf.Value = redactAll(lf.Value, secrets)
+			out = append(out, lf)
+		}
+	}
+	return out
+}
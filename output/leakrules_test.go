@@ -0,0 +1,41 @@
+package output
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestLeakRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		rule  string
+		match string
+	}{
+		{"Via", "1.1 varnish", "", ""},
+		{"Via", "HTTP/1.1 proxy", "", ""},
+		{"Via", "HTTP/2.0 edge, https/1.1 gw", "", ""},
+		{"Upgrade", "h2c/1.0, HTTP/2.0", "", ""},
+		{"Via", "HTTP/1.1 proxy (squid/5.7)", "value/version-string", "squid/5.7"},
+		{"X-Proxy", "1.1 varnish (Varnish/6.0)", "value/version-string", "Varnish/6.0"},
+		{"X-Engine", "Apache/2.4.41, PHP/8.1.2", "value/version-string", "Apache/2.4.41"},
+		{"Location", "https://example.com/app/v1.2/", "", ""},
+		{"X-Error", "Traceback (most recent call last)", "value/stack-trace", "Traceback (most recent call last)"},
+		{"X-Trace", "/var/www/html/index.php", "value/file-path", "/var/www/html/index.php"},
+		{"X-Debug-Token", "abc123", "name/symfony-profiler", ""},
+		{"X-Powered-By", "Express", "known/leak-header", ""},
+	}
+	for _, tt := range tests {
+		var rule, match string
+		for _, set := range leakRuleSets {
+			for _, r := range set.Rules {
+				if lf, ok := r.apply(http.CanonicalHeaderKey(tt.name), []string{tt.value}); ok && rule == "" {
+					rule, match = set.Name+"/"+r.Name, lf.Match
+				}
+			}
+		}
+		if rule != tt.rule || match != tt.match {
+			t.Errorf("%s: %s matched %q (%q), want %q (%q)", tt.name, tt.value, rule, match, tt.rule, tt.match)
+		}
+	}
+}
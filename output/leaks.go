@@ -0,0 +1,254 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Disclosure levels of a leak header, from least to most specific.
+const (
+	DisclosureProduct = "product"
+	DisclosurePartial = "partial-version"
+	DisclosureExact   = "exact-version"
+)
+
+// Product is a product token parsed from a header value, such as
+// Apache/2.4.41 (Ubuntu).
+type Product struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+func (p Product) String() string {
+	s := p.Name
+	if p.Version != "" {
+		s += " " + p.Version
+	}
+	if p.Comment != "" {
+		s += " (" + p.Comment + ")"
+	}
+	return s
+}
+
+// productHeaders carry product tokens (RFC 9110 §10.2.4) or free-form
+// product names.
+var productHeaders = map[string]bool{
+	"Server": true, "X-Powered-By": true, "X-Server-Powered-By": true, "X-Powered-CMS": true,
+	"X-Generator": true, "X-Generated-By": true, "X-CMS": true, "X-Powered-By-Plesk": true,
+	"Powered-By": true, "X-Content-Encoded-By": true, "Product": true, "X-CF-Powered-By": true,
+	"X-Framework": true, "X-Redirect-By": true, "Liferay-Portal": true, "X-Varnish-Server": true,
+}
+
+// versionHeaders hold a bare version of the product they are named after.
+var versionHeaders = map[string]string{
+	"X-AspNet-Version":            "ASP.NET",
+	"X-AspNetMvc-Version":         "ASP.NET MVC",
+	"X-Php-Version":               "PHP",
+	"X-OWA-Version":               "Outlook Web App",
+	"X-Umbraco-Version":           "Umbraco",
+	"X-Joomla-Version":            "Joomla",
+	"X-Cocoon-Version":            "Apache Cocoon",
+	"X-Jitsi-Release":             "Jitsi Meet",
+	"OracleCommerceCloud-Version": "Oracle Commerce Cloud",
+	"X-Mod-Pagespeed":             "mod_pagespeed",
+	"X-Page-Speed":                "PageSpeed",
+}
+
+// isVersion reports whether s looks like a version number (2.4.41, v1.2).
+func isVersion(s string) bool {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// ParseProducts splits a header value into products. It understands
+// product tokens (name/version), parenthesised comments, comma-separated
+// lists and free-form names followed by a version ("WordPress 6.2").
+func ParseProducts(raw string) []Product {
+	var out []Product
+	var words []string
+	flush := func(version string) {
+		if len(words) > 0 || version != "" {
+			out = append(out, Product{Name: strings.Join(words, " "), Version: version})
+		}
+		words = nil
+	}
+
+	for i := 0; i < len(raw); {
+		switch c := raw[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == ',' || c == ';':
+			flush("")
+			i++
+		case c == '(':
+			flush("")
+			depth, end := 0, i
+			for ; end < len(raw); end++ {
+				if raw[end] == '(' {
+					depth++
+				} else if raw[end] == ')' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			comment := strings.TrimSpace(strings.Trim(raw[i:min(end+1, len(raw))], "()"))
+			if n := len(out); n > 0 && out[n-1].Comment == "" {
+				out[n-1].Comment = comment
+			}
+			i = end + 1
+		default:
+			end := i
+			for end < len(raw) && !strings.ContainsRune(" \t,;(", rune(raw[end])) {
+				end++
+			}
+			word := raw[i:end]
+			i = end
+			if name, version, ok := strings.Cut(word, "/"); ok && name != "" {
+				flush("")
+				words = []string{name}
+				flush(version)
+				continue
+			}
+			if isVersion(word) && len(words) > 0 {
+				flush(word)
+				continue
+			}
+			words = append(words, word)
+		}
+	}
+	flush("")
+
+	// A lone version without a name is not a product.
+	products := out[:0]
+	for _, p := range out {
+		if p.Name != "" {
+			products = append(products, p)
+		}
+	}
+	return products
+}
+
+// versionDisclosure grades a version string: a major version alone is
+// partial, major.minor or more pins the release.
+func versionDisclosure(v string) string {
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "v"), "V")
+	switch {
+	case v == "":
+		return DisclosureProduct
+	case strings.Contains(v, "."):
+		return DisclosureExact
+	}
+	return DisclosurePartial
+}
+
+var disclosureSeverity = map[string]string{
+	DisclosureProduct: "info",
+	DisclosurePartial: "low",
+	DisclosureExact:   "medium",
+}
+
+// lookupFold returns the entry of m whose key equals name case-insensitively,
+// since response headers arrive in canonical form (X-Aspnet-Version).
+func lookupFold[V any](m map[string]V, name string) (V, bool) {
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// analyzeLeak fills in the products of a leak finding and raises its
+// severity to the most specific disclosure among them.
+func analyzeLeak(lf *LeakFinding) {
+	if lf.Severity == "" {
+		lf.Severity = "info"
+	}
+	if product, ok := lookupFold(versionHeaders, lf.Header); ok {
+		lf.Products = []Product{{Name: product, Version: strings.TrimSpace(lf.Value)}}
+	} else if _, ok := lookupFold(productHeaders, lf.Header); ok {
+		lf.Products = ParseProducts(lf.Value)
+	} else {
+		return
+	}
+
+	rank := map[string]int{DisclosureProduct: 0, DisclosurePartial: 1, DisclosureExact: 2}
+	for _, p := range lf.Products {
+		d := DisclosureProduct
+		if isVersion(p.Version) {
+			d = versionDisclosure(p.Version)
+		}
+		if lf.Disclosure == "" || rank[d] > rank[lf.Disclosure] {
+			lf.Disclosure = d
+		}
+	}
+	if sev := disclosureSeverity[lf.Disclosure]; lf.Disclosure != "" && severityRank[sev] > severityRank[lf.Severity] {
+		lf.Severity = sev
+	}
+}
+
+// leakDetailsCLI prints every detail a finding carries, falling back to its
+// category and rule when it has none.
+func leakDetailsCLI(lf LeakFinding, vert string) {
+	printed := false
+	if len(lf.Products) > 0 {
+		names := make([]string, len(lf.Products))
+		for i, p := range lf.Products {
+			names[i] = p.String()
+		}
+		fmt.Printf(" %s  → [%s] %s disclosed: %s\n", vert, lf.Severity, strings.ReplaceAll(lf.Disclosure, "-", " "), strings.Join(names, ", "))
+		printed = true
+	}
+	if lf.Detector != "" {
+		fmt.Printf(" %s  → [%s] secret matched by the %s detector: %s\n", vert, lf.Severity, lf.Detector, lf.Match)
+		printed = true
+	}
+	if lf.ETag != nil {
+		fmt.Printf(" %s  → [%s] %s ETag discloses %s\n", vert, lf.Severity, lf.ETag.Format, lf.ETag)
+		printed = true
+	}
+	if lf.Category == "timing" {
+		fmt.Printf(" %s  → [%s] %s\n", vert, lf.Severity, timingDetail(lf))
+		for _, m := range lf.Metrics {
+			fmt.Printf(" %s    %s\n", vert, m)
+		}
+		printed = true
+	}
+	if !printed || (lf.Match != "" && lf.Detector == "" && lf.Category != "timing") {
+		detail := lf.Category
+		if lf.Match != "" && lf.Match != lf.Value {
+			detail += ": " + lf.Match
+		}
+		fmt.Printf(" %s  → [%s] %s (%s)\n", vert, lf.Severity, detail, lf.Rule)
+	}
+}
+
+func leaksCLI(present []LeakFinding) {
+	section("Information-Leak Headers")
+	if len(present) == 0 {
+		fmt.Printf(" %s %s None found\n\n", "└─", green("["+tick+"]"))
+		return
+	}
+	for idx, lf := range present {
+		last := idx == len(present)-1
+		branch, vert := "├─", "│"
+		if last {
+			branch, vert = "└─", " "
+		}
+		icon := yellow("[" + warn + "]")
+		if lf.Severity == "medium" || lf.Severity == "high" {
+			icon = red("[" + warn + "]")
+		}
+		fmt.Printf(" %s %s %s: %s\n", branch, icon, lf.Header, lf.Value)
+		if ShowRecommendedDetails {
+			leakDetailsCLI(lf, vert)
+		}
+		if !last {
+			fmt.Println(" │")
+		}
+	}
+	fmt.Println()
+}
@@ -79,11 +79,10 @@ var recommended = map[string]string{
 	"X-Permitted-Cross-Domain-Policies": "none",
 	"Referrer-Policy":                   "no-referrer",
 	"Clear-Site-Data":                   `"cache","cookies","storage"`,
-	"Cross-Origin-Embedder-Policy":      "require-corp",
-	"Cross-Origin-Opener-Policy":        "same-origin",
-	"Cross-Origin-Resource-Policy":      "same-origin",
 	"Permissions-Policy":                `accelerometer=(), autoplay=(), camera=(), cross-origin-isolated=(), display-capture=(), encrypted-media=(), fullscreen=(), geolocation=(), gyroscope=(), keyboard-map=(), magnetometer=(), microphone=(), midi=(), payment=(), picture-in-picture=(), publickey-credentials-get=(), screen-wake-lock=(), sync-xhr=(self), usb=(), web-share=(), xr-spatial-tracking=(), clipboard-read=(), clipboard-write=(), gamepad=(), hid=(), idle-detection=(), interest-cohort=(), serial=(), unload=()`,
 	"Cache-Control":                     "no-cache, no-store, must-revalidate",
+	// The Content-Type to recommend depends on the content, see recDerived.
+	"Content-Type": "",
 }
 
 var leaks = []string{
@@ -98,45 +97,224 @@ var deprecated = map[string]string{
 	"Feature-Policy":   "Permissions-Policy",
 }
 
+// Issue is a single weakness found while analysing a header value.
+type Issue struct {
+	Severity  string `json:"severity"`
+	Directive string `json:"directive,omitempty"`
+	Message   string `json:"message"`
+}
+
 type RecFinding struct {
-	Header      string `json:"header"`
-	Status      string `json:"status"`
-	Observed    string `json:"observed,omitempty"`
-	Recommended string `json:"recommended,omitempty"`
+	Header      string  `json:"header"`
+	Status      string  `json:"status"`
+	Observed    string  `json:"observed,omitempty"`
+	Recommended string  `json:"recommended,omitempty"`
+	Verdict     string  `json:"verdict,omitempty"`
+	Issues      []Issue `json:"issues,omitempty"`
+
+	// SyntaxError locates the parse failure of an invalid value.
+	SyntaxError *SyntaxError `json:"syntax_error,omitempty"`
+	// Features holds the per-feature breakdown of Permissions-Policy.
+	Features []FeatureVerdict `json:"features,omitempty"`
+}
+
+// recCheck analyses the value of a recommended header that is present in the
+// response and fills in Status and Issues. Headers without a recCheck are
+// compared literally against their recommended value.
+type recCheck func(f *RecFinding, val string, resp *http.Response, ev *Evidence)
+
+var recChecks = map[string]recCheck{
+	"Content-Security-Policy":   checkCSP,
+	"Strict-Transport-Security": checkHSTS,
+	"Permissions-Policy":        checkPermissionsPolicy,
+	"Cache-Control":             checkCacheControl,
+	"Clear-Site-Data":           checkClearSiteData,
+	"Content-Type":              checkContentType,
+}
+
+// recExpected reports whether a recommended header belongs on this response.
+// Headers listed here are only flagged as missing where it returns true;
+// headers not listed are expected everywhere.
+var recExpected = map[string]func(ev *Evidence) bool{
+	"Clear-Site-Data": func(ev *Evidence) bool { return ev.Logout },
+}
+
+// recDerived computes the recommended value of headers that depend on the
+// response, given the observed value (possibly empty or invalid). It replaces
+// the entry in recommended and may return "" when there is nothing to advise.
+var recDerived = map[string]func(val string, ev *Evidence) string{
+	"Content-Type": contentTypeRecommendation,
+}
+
+// evaluateRec grades hdr on resp. It returns false when the header is absent
+// and not expected on this kind of response.
+func evaluateRec(hdr string, resp *http.Response, ev *Evidence) (RecFinding, bool) {
+	want := recommended[hdr]
+	val := strings.TrimSpace(resp.Header.Get(hdr))
+	if derive, ok := recDerived[hdr]; ok {
+		want = derive(val, ev)
+	}
+	f := RecFinding{
+		Header:      hdr,
+		Recommended: want,
+	}
+	if val == "" {
+		if expected, ok := recExpected[hdr]; ok && !expected(ev) {
+			return f, false
+		}
+		f.Status = "missing"
+		return f, true
+	}
+	if validate, ok := recSyntax[hdr]; ok {
+		if err := validate(val); err != nil {
+			f.Status, f.Observed, f.SyntaxError = StatusInvalid, val, err
+			return f, true
+		}
+	}
+	if check, ok := recChecks[hdr]; ok {
+		check(&f, val, resp, ev)
+	} else if strings.EqualFold(val, want) {
+		f.Status = "ok"
+	} else {
+		f.Status = "different"
+	}
+	if f.Status != "ok" {
+		f.Observed = val
+	}
+	return f, true
+}
+
+// recFindings evaluates every recommended header that applies to resp.
+func recFindings(resp *http.Response, ev *Evidence) []RecFinding {
+	var out []RecFinding
+	for _, hdr := range recKeys() {
+		if f, ok := evaluateRec(hdr, resp, ev); ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func recKeys() []string {
+	keys := make([]string, 0, len(recommended))
+	for k := range recommended {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
+// hasSerious reports whether any issue is worse than informational.
+func hasSerious(issues []Issue) bool {
+	for _, is := range issues {
+		if is.Severity == "high" || is.Severity == "medium" {
+			return true
+		}
+	}
+	return false
+}
+
+func issueLine(is Issue) string {
+	if is.Directive != "" {
+		return fmt.Sprintf("[%s] %s: %s", is.Severity, is.Directive, is.Message)
+	}
+	return fmt.Sprintf("[%s] %s", is.Severity, is.Message)
+}
+
+// LeakFinding is a header that discloses information about the server.
+// Products and Disclosure are set for headers naming software, Detector for
+// secrets, whose Value and Match are redacted, ETag for ETags that decode to
+// file metadata and Metrics for Server-Timing.
 type LeakFinding struct {
-	Header string `json:"header"`
-	Value  string `json:"value"`
+	Header     string               `json:"header"`
+	Value      string               `json:"value"`
+	Rule       string               `json:"rule"`
+	Category   string               `json:"category"`
+	Detector   string               `json:"detector,omitempty"`
+	Match      string               `json:"match,omitempty"`
+	Products   []Product            `json:"products,omitempty"`
+	Disclosure string               `json:"disclosure,omitempty"`
+	ETag       *ETagInfo            `json:"etag,omitempty"`
+	Metrics    []ServerTimingMetric `json:"metrics,omitempty"`
+	Severity   string               `json:"severity,omitempty"`
+}
+
+// Checks selects the sections ProduceCLI and ProduceJSON report.
+type Checks struct {
+	Rec, Leak, Depr, Cookies, CORS, Reporting, Edge, AltSvc bool
+}
+
+// Evidence carries what the scanner gathered about a target beyond the
+// response itself. Sections backed by a nil field are not reported.
+type Evidence struct {
+	Preload    *PreloadResult
+	CORSProbes []CORSProbe
+	// Logout marks the target as a logout endpoint, where Clear-Site-Data
+	// is expected.
+	Logout bool
+	// Body holds at most the first 512 bytes of a GET response to the
+	// target, for content sniffing. It is nil when no sample was taken.
+	Body []byte
+	// Edge lists the detected CDNs and WAFs, nil when not checked.
+	Edge *EdgeReport
+	// Technologies is the fingerprinted stack, nil when not fingerprinted.
+	Technologies []Technology
+	// Vulns lists the CVEs matching versions disclosed to the leak check.
+	// It is nil when no vulnerability database is loaded or the leak check
+	// is not selected.
+	Vulns []Vulnerability
 }
 
 type result struct {
-	URL         string        `json:"url"`
-	Recommended []RecFinding  `json:"recommended,omitempty"`
-	Leaks       []LeakFinding `json:"leaks,omitempty"`
-	Deprecated  []string      `json:"deprecated,omitempty"`
+	URL          string             `json:"url"`
+	Logout       bool               `json:"logout_endpoint,omitempty"`
+	Recommended  []RecFinding       `json:"recommended,omitempty"`
+	Framing      *FramingReport     `json:"framing,omitempty"`
+	Isolation    *IsolationReport   `json:"isolation,omitempty"`
+	Duplicates   []DuplicateFinding `json:"duplicates,omitempty"`
+	Preload      *PreloadResult     `json:"preload,omitempty"`
+	Cookies      []CookieFinding    `json:"cookies,omitempty"`
+	CORS         *CORSReport        `json:"cors,omitempty"`
+	CORSProbes   []CORSProbe        `json:"cors_probes,omitempty"`
+	Reporting    *ReportingReport   `json:"reporting,omitempty"`
+	AltSvc       *AltSvcReport      `json:"alt_svc,omitempty"`
+	Edge         *EdgeReport        `json:"edge,omitempty"`
+	Leaks        []LeakFinding      `json:"leaks,omitempty"`
+	Technologies []Technology       `json:"technologies,omitempty"`
+	Vulns        []Vulnerability    `json:"vulnerabilities,omitempty"`
+	Deprecated   []string           `json:"deprecated,omitempty"`
 }
 
 func section(title string) {
 	fmt.Fprintln(os.Stdout, yellowBold("[+] "+title))
 }
 
-func ProduceCLI(u string, resp *http.Response, doRec, doLeak, doDep bool) {
-	fmt.Printf("%sAnalyzing:%s %s\n\n", Bold, Reset, u)
+func ProduceCLI(u string, resp *http.Response, ev *Evidence, checks Checks) {
+	if ev == nil {
+		ev = &Evidence{}
+	}
+	if ev.Logout {
+		fmt.Printf("%sAnalyzing:%s %s (logout endpoint)\n\n", Bold, Reset, u)
+	} else {
+		fmt.Printf("%sAnalyzing:%s %s\n\n", Bold, Reset, u)
+	}
 	arrow := "→"
 
-	if doRec {
+	// Leaks are detected on the raw headers and redact their own findings;
+	// every other section only sees the redacted response.
+	var leaks []LeakFinding
+	if checks.Leak {
+		leaks = AnalyzeLeaks(resp)
+	}
+	resp = RedactResponse(resp)
+
+	if checks.Rec {
 		section("Recommended Security Headers")
-		keys := make([]string, 0, len(recommended))
-		for k := range recommended {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
+		findings := recFindings(resp, ev)
 
-		for idx, hdr := range keys {
-			want := recommended[hdr]
-			val := strings.TrimSpace(resp.Header.Get(hdr))
-			last := idx == len(keys)-1
+		for idx, f := range findings {
+			hdr := f.Header
+			last := idx == len(findings)-1
 			branch := "├─"
 			if last {
 				branch = "└─"
@@ -147,27 +325,51 @@ func ProduceCLI(u string, resp *http.Response, doRec, doLeak, doDep bool) {
 				vert = " "
 			}
 
+			// A feature table replaces the raw value and the recommended
+			// one, which are too long to be readable once wrapped.
+			observed := f.Observed
+			table := []string{}
+			if len(f.Features) > 0 && ShowRecommendedDetails {
+				observed = ""
+				table = featureTable(f.Features)
+			}
+
 			icon := red("[" + cross + "]")
 			lines := []string{}
 			switch {
-			case val == "":
-				
+			case f.Status == "missing":
 				lines = append(lines, "MISSING")
 			case !ShowRecommendedDetails:
-				
 				icon = green("[" + tick + "]")
 				lines = append(lines, "PRESENT")
-			case strings.EqualFold(val, want):
-				
+			case f.Status == "ok":
 				icon = green("[" + tick + "]")
 				lines = append(lines, "OK")
+			case f.Status == "weak":
+				icon = yellow("[" + warn + "]")
+				lines = append(lines, strings.TrimSpace("WEAK "+observed))
+			case f.Status == StatusInvalid || f.Status == ContentMismatch:
+				lines = append(lines, strings.TrimSpace(strings.ToUpper(f.Status)+" "+observed))
+			case f.Status == ContentNoCharset:
+				icon = yellow("[" + warn + "]")
+				lines = append(lines, strings.TrimSpace("NO CHARSET "+observed))
 			default:
-				
 				icon = yellow("[" + warn + "]")
-				lines = append(lines, fmt.Sprintf("DIFF %s", val))
+				lines = append(lines, strings.TrimSpace("DIFF "+observed))
 			}
-			if ShowRecommendedDetails && (val == "" || !strings.EqualFold(val, want)) {
-				lines = append(lines, fmt.Sprintf("Recommended: %s", want))
+			if ShowRecommendedDetails {
+				if f.SyntaxError != nil {
+					lines = append(lines, fmt.Sprintf("Syntax error at %v near %s", f.SyntaxError, syntaxContext(f.Observed, f.SyntaxError.Pos)))
+				}
+				if f.Verdict != "" {
+					lines = append(lines, "Verdict: "+verdictText(f.Verdict))
+				}
+				for _, is := range f.Issues {
+					lines = append(lines, issueLine(is))
+				}
+				if f.Status != "ok" && len(table) == 0 && f.Recommended != "" {
+					lines = append(lines, fmt.Sprintf("Recommended: %s", f.Recommended))
+				}
 			}
 
 			fmt.Printf(" %s %s %s\n", branch, icon, hdr)
@@ -191,6 +393,9 @@ func ProduceCLI(u string, resp *http.Response, doRec, doLeak, doDep bool) {
 					}
 				}
 			}
+			for _, row := range table {
+				fmt.Printf(" %s    %s\n", vert, row)
+			}
 			if !last {
 				fmt.Println(" │")
 			}
@@ -198,36 +403,53 @@ func ProduceCLI(u string, resp *http.Response, doRec, doLeak, doDep bool) {
 		fmt.Println()
 	}
 
-	if doLeak {
-		section("Information-Leak Headers")
-		present := []LeakFinding{}
-		for _, hdr := range leaks {
-			if val := strings.TrimSpace(resp.Header.Get(hdr)); val != "" {
-				present = append(present, LeakFinding{
-					Header: hdr,
-					Value:  val,
-				})
-			}
-		}
-		if len(present) == 0 {
-			fmt.Printf(" %s %s None found\n\n", "└─", green("["+tick+"]"))
-		} else {
-			for idx, lf := range present {
-				last := idx == len(present)-1
-				branch := "├─"
-				if last {
-					branch = "└─"
-				}
-				fmt.Printf(" %s %s %s: %s\n", branch, yellow("[!]"), lf.Header, lf.Value)
-				if !last {
-					fmt.Println(" │")
-				}
-			}
-			fmt.Println()
-		}
+	if checks.Rec {
+		framingCLI(AnalyzeFraming(resp))
+		isolationCLI(AnalyzeIsolation(resp))
+		duplicatesCLI(AnalyzeDuplicates(resp))
+	}
+
+	if ev.Preload != nil {
+		preloadCLI(ev.Preload)
+	}
+
+	if checks.Cookies {
+		cookiesCLI(AuditCookies(resp))
+	}
+
+	if checks.CORS {
+		corsCLI(AnalyzeCORS(resp))
+	}
+
+	if len(ev.CORSProbes) > 0 {
+		corsProbesCLI(ev.CORSProbes)
+	}
+
+	if checks.Reporting {
+		reportingCLI(AnalyzeReporting(resp))
+	}
+
+	if checks.AltSvc {
+		altSvcCLI(AnalyzeAltSvc(resp))
+	}
+
+	if ev.Edge != nil {
+		edgeCLI(ev.Edge)
+	}
+
+	if checks.Leak {
+		leaksCLI(leaks)
+	}
+
+	if ev.Technologies != nil {
+		technologiesCLI(ev.Technologies)
 	}
 
-	if doDep {
+	if checks.Leak && ev.Vulns != nil {
+		vulnsCLI(ev.Vulns)
+	}
+
+	if checks.Depr {
 		section("Deprecated Headers")
 		present := []string{}
 		for hdr := range deprecated { // for _, hdr := range deprecated {
@@ -256,43 +478,50 @@ func ProduceCLI(u string, resp *http.Response, doRec, doLeak, doDep bool) {
 	}
 }
 
-func ProduceJSON(u string, resp *http.Response, doRec, doLeak, doDep bool) []byte {
+func ProduceJSON(u string, resp *http.Response, ev *Evidence, checks Checks) []byte {
+	if ev == nil {
+		ev = &Evidence{}
+	}
 	res := result{
-		URL: u,
+		URL:        u,
+		Logout:     ev.Logout,
+		Preload:    ev.Preload,
+		CORSProbes: ev.CORSProbes,
+		Edge:       ev.Edge,
+
+		Technologies: ev.Technologies,
 	}
 
-	if doRec {
-		for hdr, want := range recommended {
-			val := strings.TrimSpace(resp.Header.Get(hdr))
-			f := RecFinding{
-				Header:      hdr,
-				Recommended: want,
-			}
-			switch {
-			case val == "":
-				f.Status = "missing"
-			case strings.EqualFold(val, want):
-				f.Status = "ok"
-			default:
-				f.Status = "different"
-				f.Observed = val
-			}
-			res.Recommended = append(res.Recommended, f)
-		}
+	if checks.Leak {
+		res.Leaks = AnalyzeLeaks(resp)
+		res.Vulns = ev.Vulns
 	}
+	resp = RedactResponse(resp)
 
-	if doLeak {
-		for _, hdr := range leaks {
-			if val := strings.TrimSpace(resp.Header.Get(hdr)); val != "" {
-				res.Leaks = append(res.Leaks, LeakFinding{
-					Header: hdr,
-					Value:  val,
-				})
-			}
-		}
+	if checks.Rec {
+		res.Recommended = recFindings(resp, ev)
+		res.Framing = AnalyzeFraming(resp)
+		res.Isolation = AnalyzeIsolation(resp)
+		res.Duplicates = AnalyzeDuplicates(resp)
+	}
+
+	if checks.Cookies {
+		res.Cookies = AuditCookies(resp)
+	}
+
+	if checks.CORS {
+		res.CORS = AnalyzeCORS(resp)
+	}
+
+	if checks.Reporting {
+		res.Reporting = AnalyzeReporting(resp)
+	}
+
+	if checks.AltSvc {
+		res.AltSvc = AnalyzeAltSvc(resp)
 	}
 
-	if doDep {
+	if checks.Depr {
 		for _, hdr := range deprecated {
 			if resp.Header.Get(hdr) != "" {
 				res.Deprecated = append(res.Deprecated, hdr)
@@ -0,0 +1,238 @@
+package output
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// FeatureVerdict describes how a Permissions-Policy feature is delegated.
+// Verdict is one of disabled, self, third-party, all, absent, unknown or
+// invalid.
+type FeatureVerdict struct {
+	Feature   string `json:"feature"`
+	Allowlist string `json:"allowlist,omitempty"`
+	Verdict   string `json:"verdict"`
+}
+
+// powerfulFeatures gate access to sensitive device capabilities or data and
+// should never be delegated to arbitrary origins.
+var powerfulFeatures = map[string]bool{
+	"camera": true, "microphone": true, "geolocation": true, "payment": true,
+	"usb": true, "serial": true, "hid": true, "bluetooth": true,
+	"display-capture": true, "clipboard-read": true, "clipboard-write": true,
+	"midi": true, "publickey-credentials-get": true, "identity-credentials-get": true,
+	"local-fonts": true, "window-management": true, "xr-spatial-tracking": true,
+	"accelerometer": true, "gyroscope": true, "magnetometer": true,
+}
+
+// knownFeatures are the policy-controlled features browsers recognise, in
+// addition to the powerful ones and any ch-* client hint.
+var knownFeatures = map[string]bool{
+	"ambient-light-sensor": true, "attribution-reporting": true, "autoplay": true,
+	"browsing-topics": true, "compute-pressure": true, "cross-origin-isolated": true,
+	"deferred-fetch": true, "digital-credentials-get": true, "direct-sockets": true,
+	"document-domain": true, "encrypted-media": true, "execution-while-not-rendered": true,
+	"execution-while-out-of-viewport": true, "focus-without-user-activation": true,
+	"fullscreen": true, "gamepad": true, "idle-detection": true, "interest-cohort": true,
+	"join-ad-interest-group": true, "keyboard-map": true, "otp-credentials": true,
+	"picture-in-picture": true, "private-aggregation": true,
+	"private-state-token-issuance": true, "private-state-token-redemption": true,
+	"publickey-credentials-create": true, "run-ad-auction": true, "screen-wake-lock": true,
+	"shared-storage": true, "shared-storage-select-url": true, "smart-card": true,
+	"speaker-selection": true, "storage-access": true, "sync-xhr": true, "unload": true,
+	"vertical-scroll": true, "web-share": true, "captured-surface-control": true,
+	"language-detector": true, "summarizer": true, "translator": true, "writer": true,
+	"rewriter": true, "autofill": true,
+}
+
+func isKnownFeature(f string) bool {
+	return powerfulFeatures[f] || knownFeatures[f] || strings.HasPrefix(f, "ch-")
+}
+
+// allowlist classifies a member's value: "disabled" for (), "self" when only
+// self is listed, "all" when * is present, "third-party" when explicit
+// origins are listed and "invalid" for anything browsers reject.
+func allowlist(m SFMember) (string, []string) {
+	items := m.List
+	if !m.IsList {
+		items = []SFItem{m.Item}
+	}
+	var (
+		self, all bool
+		origins   []string
+	)
+	for _, it := range items {
+		switch v := it.Value.(type) {
+		case SFToken:
+			switch v {
+			case "*":
+				all = true
+			case "self":
+				self = true
+			case "src":
+				// only meaningful in iframe allow attributes
+			default:
+				return "invalid", nil
+			}
+		case string:
+			origins = append(origins, v)
+		default:
+			return "invalid", nil
+		}
+	}
+	switch {
+	case all:
+		return "all", origins
+	case len(origins) > 0:
+		return "third-party", origins
+	case self:
+		return "self", nil
+	}
+	return "disabled", nil
+}
+
+func serializeAllowlist(m SFMember) string {
+	items := m.List
+	if !m.IsList {
+		return sfItemString(m.Item)
+	}
+	parts := make([]string, len(items))
+	for i, it := range items {
+		parts[i] = sfItemString(it)
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+func sfItemString(it SFItem) string {
+	switch v := it.Value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case SFToken:
+		return string(v)
+	}
+	return fmt.Sprint(it.Value)
+}
+
+// closestFeature suggests a known feature for a misspelled one.
+func closestFeature(f string) string {
+	best, bestDist := "", 3
+	for _, set := range []map[string]bool{powerfulFeatures, knownFeatures} {
+		for k := range set {
+			if d := editDistance(f, k); d < bestDist || (d == bestDist && best != "" && k < best) {
+				best, bestDist = k, d
+			}
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// AnalyzePermissionsPolicy returns a verdict per feature, including the
+// recommended features that the policy leaves at their default, and the
+// issues worth reporting.
+func AnalyzePermissionsPolicy(members []SFMember) ([]FeatureVerdict, []Issue) {
+	var (
+		verdicts []FeatureVerdict
+		issues   []Issue
+		seen     = map[string]bool{}
+	)
+	for _, m := range members {
+		seen[m.Key] = true
+		v := FeatureVerdict{Feature: m.Key, Allowlist: serializeAllowlist(m)}
+		kind, origins := allowlist(m)
+		v.Verdict = kind
+
+		if !isKnownFeature(m.Key) {
+			v.Verdict = "unknown"
+			msg := "unknown feature, ignored by browsers"
+			if s := closestFeature(m.Key); s != "" {
+				msg += fmt.Sprintf(" (did you mean %s?)", s)
+			}
+			issues = append(issues, Issue{Severity: "low", Directive: m.Key, Message: msg})
+			verdicts = append(verdicts, v)
+			continue
+		}
+
+		switch kind {
+		case "invalid":
+			issues = append(issues, Issue{Severity: "medium", Directive: m.Key, Message: fmt.Sprintf("allowlist %s is invalid, the feature keeps its default policy", v.Allowlist)})
+		case "all":
+			sev := "low"
+			if powerfulFeatures[m.Key] {
+				sev = "high"
+			}
+			issues = append(issues, Issue{Severity: sev, Directive: m.Key, Message: "allowed for every origin (*)"})
+		case "third-party":
+			if powerfulFeatures[m.Key] {
+				issues = append(issues, Issue{Severity: "medium", Directive: m.Key, Message: "delegated to third-party origins " + strings.Join(origins, ", ")})
+			}
+		}
+		verdicts = append(verdicts, v)
+	}
+
+	var absent []string
+	for _, m := range recommendedPermissions {
+		if !seen[m.Key] {
+			absent = append(absent, m.Key)
+			verdicts = append(verdicts, FeatureVerdict{Feature: m.Key, Verdict: "absent"})
+		}
+	}
+	if len(absent) > 0 {
+		sort.Strings(absent)
+		issues = append(issues, Issue{Severity: "info", Message: "recommended features left at their default policy: " + strings.Join(absent, ", ")})
+	}
+	return verdicts, issues
+}
+
+var recommendedPermissions, _ = ParseSFDictionary(recommended["Permissions-Policy"])
+
+func checkPermissionsPolicy(f *RecFinding, val string, _ *http.Response, _ *Evidence) {
+	// recSyntax has already rejected values that don't parse.
+	members, _ := ParseSFDictionary(val)
+	f.Features, f.Issues = AnalyzePermissionsPolicy(members)
+	if hasSerious(f.Issues) {
+		f.Status = "weak"
+	} else {
+		f.Status = "ok"
+	}
+}
+
+// featureTable renders verdicts as aligned rows for the CLI. Absent
+// features are left out: they are already summarised in an issue.
+func featureTable(verdicts []FeatureVerdict) []string {
+	width := len("FEATURE")
+	for _, v := range verdicts {
+		if v.Verdict != "absent" {
+			width = max(width, len(v.Feature))
+		}
+	}
+	rows := []string{fmt.Sprintf("%-*s  %-11s  %s", width, "FEATURE", "VERDICT", "ALLOWLIST")}
+	for _, v := range verdicts {
+		if v.Verdict == "absent" {
+			continue
+		}
+		rows = append(rows, fmt.Sprintf("%-*s  %-11s  %s", width, v.Feature, v.Verdict, v.Allowlist))
+	}
+	return rows
+}
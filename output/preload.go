@@ -0,0 +1,75 @@
+package output
+
+import "fmt"
+
+// PreloadRequirement is one of the hstspreload.org submission requirements.
+type PreloadRequirement struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// PreloadResult is the HSTS preload eligibility verdict for the registrable
+// domain of a target. Eligible is true when every requirement passed.
+// Preloaded is nil when the list in use is the bundled sample and the domain
+// is not in it, since it may still be on the real list.
+type PreloadResult struct {
+	Host         string               `json:"host"`
+	Eligible     bool                 `json:"eligible"`
+	Preloaded    *bool                `json:"preloaded,omitempty"`
+	ListEntry    string               `json:"list_entry,omitempty"`
+	Requirements []PreloadRequirement `json:"requirements"`
+}
+
+// Require appends a requirement and keeps Eligible in sync.
+func (p *PreloadResult) Require(name string, passed bool, format string, a ...interface{}) {
+	if len(p.Requirements) == 0 {
+		p.Eligible = true
+	}
+	p.Requirements = append(p.Requirements, PreloadRequirement{
+		Name:   name,
+		Passed: passed,
+		Detail: fmt.Sprintf(format, a...),
+	})
+	p.Eligible = p.Eligible && passed
+}
+
+func preloadCLI(p *PreloadResult) {
+	section("HSTS Preload Eligibility")
+
+	verdict := red("[" + cross + "]")
+	text := "NOT ELIGIBLE"
+	if p.Eligible {
+		verdict = green("[" + tick + "]")
+		text = "ELIGIBLE"
+	}
+	fmt.Printf(" ├─ %s %s: %s\n", verdict, p.Host, text)
+	fmt.Println(" │")
+
+	icon, listed := yellow("["+warn+"]"), "unknown (not in the bundled sample, pass -preload-list for the full list)"
+	switch {
+	case p.Preloaded == nil:
+	case *p.Preloaded:
+		icon, listed = green("["+tick+"]"), fmt.Sprintf("already preloaded (entry %q)", p.ListEntry)
+	default:
+		listed = "not on the preload list"
+	}
+	fmt.Printf(" ├─ %s Preload list: %s\n", icon, listed)
+
+	for idx, r := range p.Requirements {
+		fmt.Println(" │")
+		branch, vert := "├─", "│"
+		if idx == len(p.Requirements)-1 {
+			branch, vert = "└─", " "
+		}
+		icon := green("[" + tick + "]")
+		if !r.Passed {
+			icon = red("[" + cross + "]")
+		}
+		fmt.Printf(" %s %s %s\n", branch, icon, r.Name)
+		if r.Detail != "" {
+			fmt.Printf(" %s  → %s\n", vert, r.Detail)
+		}
+	}
+	fmt.Println()
+}
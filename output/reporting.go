@@ -0,0 +1,291 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ReportEndpoint is a named endpoint group declared by Reporting-Endpoints
+// or Report-To.
+type ReportEndpoint struct {
+	Group  string   `json:"group"`
+	URLs   []string `json:"urls"`
+	Source string   `json:"source"`
+}
+
+// ReportReference is a header or directive that delivers reports to a
+// named group. Defined tells whether the group exists on the response.
+type ReportReference struct {
+	Source  string `json:"source"`
+	Group   string `json:"group"`
+	Defined bool   `json:"defined"`
+}
+
+// NELPolicy is a parsed Network Error Logging policy.
+type NELPolicy struct {
+	ReportTo          string  `json:"report_to"`
+	MaxAge            int64   `json:"max_age"`
+	IncludeSubdomains bool    `json:"include_subdomains,omitempty"`
+	SuccessFraction   float64 `json:"success_fraction"`
+	FailureFraction   float64 `json:"failure_fraction"`
+}
+
+// ReportingReport covers Reporting-Endpoints, Report-To, NEL and every
+// header that refers to their groups.
+type ReportingReport struct {
+	Endpoints  []ReportEndpoint  `json:"endpoints,omitempty"`
+	References []ReportReference `json:"references,omitempty"`
+	NEL        *NELPolicy        `json:"nel,omitempty"`
+	Issues     []Issue           `json:"issues,omitempty"`
+}
+
+type reportToGroup struct {
+	Group     string `json:"group"`
+	MaxAge    *int64 `json:"max_age"`
+	Endpoints []struct {
+		URL string `json:"url"`
+	} `json:"endpoints"`
+	IncludeSubdomains bool `json:"include_subdomains"`
+}
+
+type nelHeader struct {
+	ReportTo          string   `json:"report_to"`
+	MaxAge            *int64   `json:"max_age"`
+	IncludeSubdomains bool     `json:"include_subdomains"`
+	SuccessFraction   *float64 `json:"success_fraction"`
+	FailureFraction   *float64 `json:"failure_fraction"`
+}
+
+// trustworthy reports whether u is potentially trustworthy: HTTPS or a
+// loopback host. The Reporting API only delivers to such URLs, and only
+// pages served from them are secure contexts.
+func trustworthy(u *url.URL) bool {
+	if u.Scheme == "https" || u.Scheme == "wss" {
+		return true
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback()
+	}
+	return host == "localhost" || strings.HasSuffix(host, ".localhost")
+}
+
+// AnalyzeReporting validates the reporting headers and cross-checks the
+// groups referenced by CSP, COOP, COEP and NEL. It returns nil when the
+// response neither declares nor references a reporting endpoint.
+func AnalyzeReporting(resp *http.Response) *ReportingReport {
+	r := &ReportingReport{}
+	add := func(sev, hdr, format string, a ...interface{}) {
+		r.Issues = append(r.Issues, Issue{Severity: sev, Directive: hdr, Message: fmt.Sprintf(format, a...)})
+	}
+
+	var base *url.URL
+	if resp.Request != nil {
+		base = resp.Request.URL
+	}
+	// checkURL resolves an endpoint URL and reports where its reports go.
+	checkURL := func(hdr, raw string, mustBeSecure bool) (string, bool) {
+		u, err := url.Parse(raw)
+		if err != nil || raw == "" {
+			add("medium", hdr, "invalid endpoint URL %q", raw)
+			return raw, false
+		}
+		if base != nil {
+			u = base.ResolveReference(u)
+		}
+		if !u.IsAbs() {
+			add("medium", hdr, "relative endpoint URL %q can't be resolved", raw)
+			return raw, false
+		}
+		switch {
+		case !trustworthy(u) && mustBeSecure:
+			add("medium", hdr, "%s is not served over HTTPS, browsers drop reports to it", u)
+		case !trustworthy(u):
+			add("low", hdr, "%s is not served over HTTPS, reports travel in cleartext", u)
+		}
+		if base != nil && RegistrableDomain(u.Hostname()) != RegistrableDomain(base.Hostname()) {
+			add("info", hdr, "reports, which include page URLs, are sent to third-party host %s", u.Hostname())
+		}
+		return u.String(), true
+	}
+
+	endpointsGroups := map[string]bool{}
+	reportToGroups := map[string]bool{}
+
+	if vals := resp.Header.Values("Reporting-Endpoints"); len(vals) > 0 {
+		hdr := "Reporting-Endpoints"
+		members, err := ParseSFDictionary(strings.Join(vals, ", "))
+		if err != nil {
+			add("medium", hdr, "not a valid structured-field dictionary, the header is ignored (%v)", err)
+		}
+		for _, m := range members {
+			s, ok := m.Item.Value.(string)
+			if m.IsList || !ok {
+				add("medium", hdr, "endpoint %s must be a quoted URL string, it is ignored", m.Key)
+				continue
+			}
+			if u, ok := checkURL(hdr, s, true); ok {
+				r.Endpoints = append(r.Endpoints, ReportEndpoint{Group: m.Key, URLs: []string{u}, Source: hdr})
+				endpointsGroups[m.Key] = true
+			}
+		}
+	}
+
+	if vals := resp.Header.Values("Report-To"); len(vals) > 0 {
+		hdr := "Report-To"
+		add("info", hdr, "deprecated in favour of Reporting-Endpoints, keep it only for NEL")
+		var groups []reportToGroup
+		if err := json.Unmarshal([]byte("["+strings.Join(vals, ",")+"]"), &groups); err != nil {
+			add("medium", hdr, "not a valid list of JSON objects, the header is ignored (%v)", err)
+		}
+		for _, g := range groups {
+			if g.Group == "" {
+				g.Group = "default"
+			}
+			if g.MaxAge == nil || *g.MaxAge < 0 {
+				add("medium", hdr, "group %s has no valid max_age, it is ignored", g.Group)
+				continue
+			}
+			if *g.MaxAge == 0 {
+				add("low", hdr, "group %s has max_age 0, which removes it", g.Group)
+				continue
+			}
+			ep := ReportEndpoint{Group: g.Group, Source: hdr}
+			for _, e := range g.Endpoints {
+				if u, ok := checkURL(hdr, e.URL, true); ok {
+					ep.URLs = append(ep.URLs, u)
+				}
+			}
+			if len(ep.URLs) == 0 {
+				add("medium", hdr, "group %s has no usable endpoint", g.Group)
+				continue
+			}
+			r.Endpoints = append(r.Endpoints, ep)
+			reportToGroups[g.Group] = true
+		}
+	}
+
+	if raw := strings.TrimSpace(resp.Header.Get("NEL")); raw != "" {
+		hdr := "NEL"
+		var n nelHeader
+		switch err := json.Unmarshal([]byte(raw), &n); {
+		case err != nil:
+			add("medium", hdr, "not a valid JSON object, the header is ignored (%v)", err)
+		case n.ReportTo == "":
+			add("medium", hdr, "report_to is missing, the policy is ignored")
+		case n.MaxAge == nil || *n.MaxAge < 0:
+			add("medium", hdr, "max_age is missing or negative, the policy is ignored")
+		default:
+			p := &NELPolicy{ReportTo: n.ReportTo, MaxAge: *n.MaxAge, IncludeSubdomains: n.IncludeSubdomains, FailureFraction: 1}
+			if n.SuccessFraction != nil {
+				p.SuccessFraction = *n.SuccessFraction
+			}
+			if n.FailureFraction != nil {
+				p.FailureFraction = *n.FailureFraction
+			}
+			for _, f := range []struct {
+				name string
+				v    float64
+			}{{"success_fraction", p.SuccessFraction}, {"failure_fraction", p.FailureFraction}} {
+				if f.v < 0 || f.v > 1 {
+					add("low", hdr, "%s %g is outside [0, 1]", f.name, f.v)
+				}
+			}
+			if p.SuccessFraction > 0 {
+				add("info", hdr, "success_fraction %g also reports successful requests, sampling regular user traffic", p.SuccessFraction)
+			}
+			r.NEL = p
+			ref := ReportReference{Source: hdr, Group: p.ReportTo, Defined: reportToGroups[p.ReportTo]}
+			if !ref.Defined && endpointsGroups[p.ReportTo] {
+				add("medium", hdr, "group %s is only declared in Reporting-Endpoints, NEL only uses Report-To groups", p.ReportTo)
+			} else if !ref.Defined {
+				add("medium", hdr, "group %s is not declared in Report-To, reports are dropped", p.ReportTo)
+			}
+			r.References = append(r.References, ref)
+		}
+	}
+
+	reference := func(source, group string) {
+		ref := ReportReference{Source: source, Group: group, Defined: endpointsGroups[group] || reportToGroups[group]}
+		if !ref.Defined {
+			add("medium", source, "group %s is not declared in Reporting-Endpoints or Report-To, reports are dropped", group)
+		}
+		r.References = append(r.References, ref)
+	}
+
+	for _, hdr := range []string{"Content-Security-Policy", "Content-Security-Policy-Report-Only"} {
+		for _, v := range resp.Header.Values(hdr) {
+			for _, raw := range strings.Split(v, ",") {
+				p := ParseCSP(raw)
+				if groups := p.Directives["report-to"]; len(groups) > 0 {
+					reference(hdr+" report-to", groups[0])
+				}
+				for _, u := range p.Directives["report-uri"] {
+					checkURL(hdr+" report-uri", u, false)
+				}
+			}
+		}
+	}
+
+	for _, hdr := range []string{
+		"Cross-Origin-Opener-Policy", "Cross-Origin-Opener-Policy-Report-Only",
+		"Cross-Origin-Embedder-Policy", "Cross-Origin-Embedder-Policy-Report-Only",
+	} {
+		raw := strings.TrimSpace(resp.Header.Get(hdr))
+		if raw == "" {
+			continue
+		}
+		it, err := ParseSFItem(raw)
+		if err != nil {
+			continue
+		}
+		for _, p := range it.Params {
+			if group, ok := p.Value.(string); ok && p.Key == "report-to" {
+				reference(hdr+" report-to", group)
+			}
+		}
+	}
+
+	if len(r.Endpoints) == 0 && len(r.References) == 0 && len(r.Issues) == 0 {
+		return nil
+	}
+	return r
+}
+
+func reportingCLI(r *ReportingReport) {
+	section("Reporting Endpoints")
+	if r == nil {
+		fmt.Printf(" %s %s No reporting headers\n\n", "└─", green("["+tick+"]"))
+		return
+	}
+
+	for _, ep := range r.Endpoints {
+		fmt.Printf(" ├─ %s → %s (%s)\n", ep.Group, strings.Join(ep.URLs, ", "), ep.Source)
+	}
+	for _, ref := range r.References {
+		icon := green("[" + tick + "]")
+		if !ref.Defined {
+			icon = red("[" + cross + "]")
+		}
+		fmt.Printf(" ├─ %s %s → %s\n", icon, ref.Source, ref.Group)
+	}
+	fmt.Println(" │")
+
+	if len(r.Issues) == 0 {
+		fmt.Printf(" └─ %s No issues\n\n", green("["+tick+"]"))
+		return
+	}
+	icon := green("[" + tick + "]")
+	if hasSerious(r.Issues) {
+		icon = yellow("[" + warn + "]")
+	}
+	fmt.Printf(" └─ %s %d issue(s)\n", icon, len(r.Issues))
+	for _, is := range r.Issues {
+		fmt.Printf("    → %s\n", issueLine(is))
+	}
+	fmt.Println()
+}
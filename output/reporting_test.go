@@ -0,0 +1,116 @@
+package output
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrustworthy(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"https://example.com/", true},
+		{"wss://example.com/", true},
+		{"http://example.com/", false},
+		{"ws://example.com/", false},
+		{"http://localhost:8080/", true},
+		{"http://app.localhost/", true},
+		{"http://127.0.0.1/", true},
+		{"http://[::1]/", true},
+		{"http://192.0.2.2/", false},
+		{"http://localhost.example.com/", false},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.in)
+		if got := trustworthy(u); got != tt.want {
+			t.Errorf("trustworthy(%s) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeReporting(t *testing.T) {
+	if r := AnalyzeReporting(reportingResponse(nil)); r != nil {
+		t.Errorf("AnalyzeReporting without reporting headers = %+v, want nil", r)
+	}
+	const reportTo = `Report-To: {"group":"nel","max_age":86400,"endpoints":[{"url":"https://example.com/nel"}]}`
+	tests := []struct {
+		headers    []string
+		endpoints  []string
+		references []string
+		issues     []string
+	}{
+		{[]string{`Reporting-Endpoints: csp="/csp", coop="https://example.com/coop"`,
+			"Content-Security-Policy: default-src 'self'; report-to csp",
+			`Cross-Origin-Opener-Policy: same-origin; report-to="coop"`},
+			[]string{"csp https://example.com/csp", "coop https://example.com/coop"},
+			[]string{"Content-Security-Policy report-to csp true", "Cross-Origin-Opener-Policy report-to coop true"}, nil},
+		// References to groups that do not exist.
+		{[]string{"Content-Security-Policy-Report-Only: default-src 'self'; report-to missing"}, nil,
+			[]string{"Content-Security-Policy-Report-Only report-to missing false"}, []string{"medium Content-Security-Policy-Report-Only report-to"}},
+		{[]string{`Reporting-Endpoints: default="https://example.com/r"`, `Cross-Origin-Embedder-Policy: require-corp; report-to="other"`},
+			[]string{"default https://example.com/r"},
+			[]string{"Cross-Origin-Embedder-Policy report-to other false"}, []string{"medium Cross-Origin-Embedder-Policy report-to"}},
+		// Endpoint URLs.
+		{[]string{`Reporting-Endpoints: a="http://example.com/r", b="https://collector.example.net/r", c=https`},
+			[]string{"a http://example.com/r", "b https://collector.example.net/r"}, nil,
+			[]string{"medium Reporting-Endpoints", "info Reporting-Endpoints", "medium Reporting-Endpoints"}},
+		{[]string{"Reporting-Endpoints: a=\"https://example.com/r\" b"}, nil, nil, []string{"medium Reporting-Endpoints"}},
+		{[]string{"Content-Security-Policy: default-src 'self'; report-uri /csp http://example.com/csp"}, nil, nil,
+			[]string{"low Content-Security-Policy report-uri"}},
+		// Report-To groups: the default name, max_age 0 and no endpoints.
+		{[]string{`Report-To: {"max_age":60,"endpoints":[{"url":"https://example.com/r"}]}`, "Content-Security-Policy: report-to default"},
+			[]string{"default https://example.com/r"}, []string{"Content-Security-Policy report-to default true"}, []string{"info Report-To"}},
+		{[]string{`Report-To: {"group":"g","max_age":0,"endpoints":[{"url":"https://example.com/r"}]}`, "Content-Security-Policy: report-to g"},
+			nil, []string{"Content-Security-Policy report-to g false"},
+			[]string{"info Report-To", "low Report-To", "medium Content-Security-Policy report-to"}},
+		{[]string{`Report-To: {"group":"g","endpoints":[{"url":"https://example.com/r"}]}`, `Report-To: {"group":"h","max_age":60,"endpoints":[]}`},
+			nil, nil, []string{"info Report-To", "medium Report-To", "medium Report-To"}},
+		{[]string{"Report-To: group=g"}, nil, nil, []string{"info Report-To", "medium Report-To"}},
+		// NEL only uses Report-To groups.
+		{[]string{reportTo, `NEL: {"report_to":"nel","max_age":86400}`}, []string{"nel https://example.com/nel"},
+			[]string{"NEL nel true"}, []string{"info Report-To"}},
+		{[]string{`Reporting-Endpoints: nel="https://example.com/nel"`, `NEL: {"report_to":"nel","max_age":86400}`}, []string{"nel https://example.com/nel"},
+			[]string{"NEL nel false"}, []string{"medium NEL"}},
+		{[]string{reportTo, `NEL: {"report_to":"nel","max_age":86400,"success_fraction":0.5,"failure_fraction":2}`}, []string{"nel https://example.com/nel"},
+			[]string{"NEL nel true"}, []string{"info Report-To", "low NEL", "info NEL"}},
+		{[]string{reportTo, `NEL: {"report_to":"nel"}`}, []string{"nel https://example.com/nel"}, nil, []string{"info Report-To", "medium NEL"}},
+		{[]string{`NEL: {"max_age":60}`}, nil, nil, []string{"medium NEL"}},
+	}
+	for _, tt := range tests {
+		r := AnalyzeReporting(reportingResponse(tt.headers))
+		if r == nil {
+			t.Errorf("AnalyzeReporting(%q) = nil", tt.headers)
+			continue
+		}
+		var endpoints, references, issues []string
+		for _, ep := range r.Endpoints {
+			endpoints = append(endpoints, ep.Group+" "+strings.Join(ep.URLs, " "))
+		}
+		for _, ref := range r.References {
+			references = append(references, fmt.Sprint(ref.Source, " ", ref.Group, " ", ref.Defined))
+		}
+		for _, is := range r.Issues {
+			issues = append(issues, is.Severity+" "+is.Directive)
+		}
+		if !reflect.DeepEqual(endpoints, tt.endpoints) || !reflect.DeepEqual(references, tt.references) || !reflect.DeepEqual(issues, tt.issues) {
+			t.Errorf("AnalyzeReporting(%q) = %q %q %q, want %q %q %q", tt.headers, endpoints, references, issues, tt.endpoints, tt.references, tt.issues)
+		}
+	}
+}
+
+// reportingResponse builds a response to https://example.com/ with the
+// given "Name: value" headers.
+func reportingResponse(headers []string) *http.Response {
+	u, _ := url.Parse("https://example.com/page")
+	resp := &http.Response{Header: http.Header{}, Request: &http.Request{URL: u}}
+	for _, h := range headers {
+		name, value, _ := strings.Cut(h, ": ")
+		resp.Header.Add(name, value)
+	}
+	return resp
+}
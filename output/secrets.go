@@ -0,0 +1,238 @@
+package output
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SecretDetector recognises credentials in header names and values. The
+// secret is the last capture group of Pattern, or the whole match. Check,
+// when set, confirms a candidate.
+type SecretDetector struct {
+	Name     string
+	Severity string
+	Pattern  *regexp.Regexp
+	Check    func(candidate string) bool
+	Skip     map[string]bool
+}
+
+// randomHeaders legitimately carry random-looking values: session cookies,
+// nonces, digests and request or trace identifiers.
+var randomHeaders = map[string]bool{
+	"Set-Cookie": true, "Etag": true, "Content-Security-Policy": true, "Content-Security-Policy-Report-Only": true,
+	"Content-Md5": true, "Digest": true, "Content-Digest": true, "Repr-Digest": true,
+	"X-Request-Id": true, "X-Correlation-Id": true, "X-Amz-Request-Id": true, "X-Amz-Id-2": true, "X-Amz-Cf-Id": true,
+	"X-Amzn-Requestid": true, "X-Amzn-Trace-Id": true, "X-Amz-Apigw-Id": true, "Cf-Ray": true,
+	"Traceparent": true, "Tracestate": true, "X-Cloud-Trace-Context": true, "X-Guploader-Uploadid": true,
+	"X-Azure-Ref": true, "X-Debug-Token": true, "X-Varnish": true,
+}
+
+var uuidPattern = regexp.MustCompile(`(?i)^[0-9a-f]{8}-?[0-9a-f]{4}-?[0-9a-f]{4}-?[0-9a-f]{4}-?[0-9a-f]{12}$`)
+
+// secretDetectors are all the available detectors, most specific first.
+var secretDetectors = []SecretDetector{
+	{Name: "private-key", Severity: "high", Pattern: regexp.MustCompile(`-----BEGIN[A-Z ]* PRIVATE KEY-----`)},
+	{Name: "aws-access-key", Severity: "high", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}\b`)},
+	{Name: "github-token", Severity: "high", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{50,})\b`)},
+	{Name: "slack-token", Severity: "high", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{Name: "stripe-key", Severity: "high", Pattern: regexp.MustCompile(`\b[sr]k_live_[A-Za-z0-9]{20,}\b`)},
+	{Name: "google-api-key", Severity: "medium", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{Name: "jwt", Severity: "medium", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), Check: isJWT, Skip: map[string]bool{"Set-Cookie": true}},
+	{Name: "bearer-token", Severity: "medium", Pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/-]{16,}=*)`)},
+	{Name: "high-entropy", Severity: "low", Pattern: regexp.MustCompile(`[A-Za-z0-9+/_.~-]{20,}={0,2}`), Check: highEntropy, Skip: randomHeaders},
+}
+
+// activeSecretDetectors are the detectors AnalyzeLeaks runs.
+var activeSecretDetectors = secretDetectors
+
+// SecretDetectorNames lists the available detectors.
+func SecretDetectorNames() []string {
+	names := make([]string, len(secretDetectors))
+	for i, d := range secretDetectors {
+		names[i] = d.Name
+	}
+	return names
+}
+
+// SelectSecretDetectors restricts secret scanning to the named detectors.
+// An empty list keeps all of them and "none" disables scanning.
+func SelectSecretDetectors(names []string) error {
+	if len(names) == 0 {
+		activeSecretDetectors = secretDetectors
+		return nil
+	}
+	var out []SecretDetector
+	for _, n := range names {
+		if strings.EqualFold(n, "none") {
+			continue
+		}
+		found := false
+		for _, d := range secretDetectors {
+			if strings.EqualFold(d.Name, n) {
+				out, found = append(out, d), true
+			}
+		}
+		if !found {
+			return fmt.Errorf("Unknown secret detector %q (available: %s)", n, strings.Join(SecretDetectorNames(), ", "))
+		}
+	}
+	activeSecretDetectors = out
+	return nil
+}
+
+// isJWT reports whether the first segment of a token decodes to a JOSE
+// header.
+func isJWT(token string) bool {
+	seg, _, _ := strings.Cut(token, ".")
+	raw, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return false
+	}
+	var hdr map[string]any
+	if json.Unmarshal(raw, &hdr) != nil {
+		return false
+	}
+	_, ok := hdr["alg"]
+	return ok
+}
+
+// shannon returns the entropy of s in bits per character.
+func shannon(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(len(s))
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// highEntropy reports whether a token looks randomly generated: hex strings
+// of at least 32 characters, or mixed-case alphanumerics with the + and / of
+// base64, with an entropy close to their alphabet. UUIDs are request
+// identifiers and dotted or path-like tokens are host, file or path names,
+// not secrets.
+func highEntropy(token string) bool {
+	token = strings.TrimRight(token, "=")
+	if uuidPattern.MatchString(token) || strings.Contains(token, ".") || pathLike(token) {
+		return false
+	}
+	var lower, upper, digit bool
+	hex := true
+	for _, c := range token {
+		switch {
+		case c >= 'a' && c <= 'z':
+			lower = true
+			hex = hex && c <= 'f'
+		case c >= 'A' && c <= 'Z':
+			upper = true
+			hex = hex && c <= 'F'
+		case c >= '0' && c <= '9':
+			digit = true
+		default:
+			hex = false
+		}
+	}
+	if hex {
+		return len(token) >= 32 && digit && shannon(token) >= 3.5
+	}
+	return lower && upper && digit && shannon(token) >= 4.2
+}
+
+// pathLike reports whether a token containing slashes is a path rather
+// than base64: absolute and directory paths, or the "//" of a URL.
+func pathLike(token string) bool {
+	return strings.HasPrefix(token, "/") || strings.HasSuffix(token, "/") || strings.Contains(token, "//")
+}
+
+// redact keeps the first and last characters of a secret so that it can be
+// recognised without being disclosed.
+func redact(secret string) string {
+	if len(secret) < 12 {
+		return secret[:min(2, len(secret))] + "****"
+	}
+	return secret[:4] + "****" + secret[len(secret)-4:]
+}
+
+// redactAll replaces every secret in s with its redacted form.
+func redactAll(s string, secrets []string) string {
+	for _, sec := range secrets {
+		s = strings.ReplaceAll(s, sec, redact(sec))
+	}
+	return s
+}
+
+// RedactHeader returns a copy of h in which every secret found by the active
+// detectors, in names and values alike, is redacted.
+func RedactHeader(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		_, secrets := secretFindings(name, values)
+		red := make([]string, len(values))
+		for i, v := range values {
+			red[i] = redactAll(v, secrets)
+		}
+		out[redactAll(name, secrets)] = red
+	}
+	return out
+}
+
+// RedactResponse returns a shallow copy of resp whose headers went through
+// RedactHeader, for the sections that quote header values.
+func RedactResponse(resp *http.Response) *http.Response {
+	clean := *resp
+	clean.Header = RedactHeader(resp.Header)
+	return &clean
+}
+
+// secretFindings scans the name and values of a header with the active
+// detectors. It also returns the raw secrets found, so that other findings on
+// the same header can be redacted.
+func secretFindings(name string, values []string) ([]LeakFinding, []string) {
+	var out []LeakFinding
+	var secrets []string
+	seen := map[string]bool{}
+	for _, d := range activeSecretDetectors {
+		if d.Skip[name] {
+			continue
+		}
+		for _, text := range append([]string{name}, values...) {
+			for _, sm := range d.Pattern.FindAllStringSubmatch(text, -1) {
+				m := sm[len(sm)-1]
+				if seen[m] || (d.Check != nil && !d.Check(m)) {
+					continue
+				}
+				seen[m] = true
+				secrets = append(secrets, m)
+				out = append(out, LeakFinding{
+					Header:   name,
+					Value:    strings.TrimSpace(text),
+					Rule:     "secret/" + d.Name,
+					Category: "secret",
+					Detector: d.Name,
+					Match:    m,
+					Severity: d.Severity,
+				})
+			}
+		}
+	}
+
+	// Longer secrets first, so that a JWT is not half-redacted by a shorter
+	// match inside it.
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	for i := range out {
+		out[i].Header = redactAll(out[i].Header, secrets)
+		out[i].Value = redactAll(out[i].Value, secrets)
+		out[i].Match = redact(out[i].Match)
+	}
+	return out, secrets
+}
@@ -0,0 +1,348 @@
+package output
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError reports where a header value stops being parseable. Pos is a
+// zero-based byte offset into the value.
+type SyntaxError struct {
+	Pos int    `json:"position"`
+	Msg string `json:"message"`
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("position %d: %s", e.Pos, e.Msg)
+}
+
+// SFToken is a Token bare item, kept distinct from String.
+type SFToken string
+
+// SFParam is a parameter attached to an item or inner list.
+type SFParam struct {
+	Key   string
+	Value interface{}
+}
+
+// SFItem is a bare item with its parameters. Value holds an int64, float64,
+// string, SFToken, []byte or bool.
+type SFItem struct {
+	Value  interface{}
+	Params []SFParam
+}
+
+// SFMember is a dictionary member: either a single Item or, when IsList is
+// set, an inner list of items with its own Params.
+type SFMember struct {
+	Key    string
+	Item   SFItem
+	IsList bool
+	List   []SFItem
+	Params []SFParam
+}
+
+// ParseSFDictionary parses a Structured Field Dictionary (RFC 8941 §4.2.2).
+// Duplicate keys keep their first position but take the last value.
+func ParseSFDictionary(s string) ([]SFMember, error) {
+	p := &sfParser{s: s}
+	p.skipSP()
+	var out []SFMember
+	index := map[string]int{}
+	for !p.eof() {
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		m := SFMember{Key: key}
+		if p.peek() == '=' {
+			p.pos++
+			if p.peek() == '(' {
+				m.IsList = true
+				if m.List, m.Params, err = p.innerList(); err != nil {
+					return nil, err
+				}
+			} else {
+				if m.Item, err = p.item(); err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			m.Item.Value = true
+			if m.Item.Params, err = p.params(); err != nil {
+				return nil, err
+			}
+		}
+		if i, dup := index[key]; dup {
+			out[i] = m
+		} else {
+			index[key] = len(out)
+			out = append(out, m)
+		}
+
+		p.skipOWS()
+		if p.eof() {
+			break
+		}
+		if p.peek() != ',' {
+			return nil, p.errorf("expected ',' between members, found %q", p.peek())
+		}
+		p.pos++
+		p.skipOWS()
+		if p.eof() {
+			return nil, p.errorf("trailing ','")
+		}
+	}
+	return out, nil
+}
+
+// ParseSFItem parses a Structured Field Item (RFC 8941 §4.2.3), such as
+// the value of Cross-Origin-Opener-Policy.
+func ParseSFItem(s string) (SFItem, error) {
+	p := &sfParser{s: s}
+	p.skipSP()
+	it, err := p.item()
+	if err != nil {
+		return SFItem{}, err
+	}
+	p.skipSP()
+	if !p.eof() {
+		return SFItem{}, p.errorf("unexpected %q after item", p.peek())
+	}
+	return it, nil
+}
+
+type sfParser struct {
+	s   string
+	pos int
+}
+
+func (p *sfParser) eof() bool { return p.pos >= len(p.s) }
+
+func (p *sfParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *sfParser) errorf(format string, a ...interface{}) error {
+	return &SyntaxError{Pos: p.pos, Msg: fmt.Sprintf(format, a...)}
+}
+
+func (p *sfParser) skipSP() {
+	for p.peek() == ' ' {
+		p.pos++
+	}
+}
+
+func (p *sfParser) skipOWS() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+func (p *sfParser) key() (string, error) {
+	c := p.peek()
+	if !(c >= 'a' && c <= 'z') && c != '*' {
+		return "", p.errorf("key must start with a lowercase letter or '*', found %q", c)
+	}
+	start := p.pos
+	for !p.eof() {
+		c = p.peek()
+		if !(c >= 'a' && c <= 'z') && !(c >= '0' && c <= '9') && !strings.ContainsRune("_-.*", rune(c)) {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *sfParser) params() ([]SFParam, error) {
+	var out []SFParam
+	for p.peek() == ';' {
+		p.pos++
+		p.skipSP()
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		var val interface{} = true
+		if p.peek() == '=' {
+			p.pos++
+			if val, err = p.bareItem(); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, SFParam{Key: key, Value: val})
+	}
+	return out, nil
+}
+
+func (p *sfParser) item() (SFItem, error) {
+	v, err := p.bareItem()
+	if err != nil {
+		return SFItem{}, err
+	}
+	params, err := p.params()
+	return SFItem{Value: v, Params: params}, err
+}
+
+func (p *sfParser) innerList() ([]SFItem, []SFParam, error) {
+	p.pos++ // '('
+	var items []SFItem
+	for {
+		p.skipSP()
+		if p.eof() {
+			return nil, nil, p.errorf("unterminated inner list")
+		}
+		if p.peek() == ')' {
+			p.pos++
+			params, err := p.params()
+			return items, params, err
+		}
+		it, err := p.item()
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, it)
+		if p.eof() {
+			return nil, nil, p.errorf("unterminated inner list")
+		}
+		if c := p.peek(); c != ' ' && c != ')' {
+			return nil, nil, p.errorf("expected ' ' or ')' in inner list, found %q", c)
+		}
+	}
+}
+
+func (p *sfParser) bareItem() (interface{}, error) {
+	c := p.peek()
+	switch {
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.number()
+	case c == '"':
+		return p.str()
+	case c == '*' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z'):
+		return p.token(), nil
+	case c == ':':
+		return p.bytes()
+	case c == '?':
+		p.pos++
+		switch p.peek() {
+		case '0':
+			p.pos++
+			return false, nil
+		case '1':
+			p.pos++
+			return true, nil
+		}
+		return nil, p.errorf("boolean must be ?0 or ?1")
+	case p.eof():
+		return nil, p.errorf("unexpected end of value")
+	}
+	return nil, p.errorf("unexpected character %q", c)
+}
+
+func (p *sfParser) number() (interface{}, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	if c := p.peek(); c < '0' || c > '9' {
+		return nil, p.errorf("expected digit, found %q", c)
+	}
+	decimal := false
+	for !p.eof() {
+		c := p.peek()
+		if c == '.' && !decimal {
+			decimal = true
+		} else if c < '0' || c > '9' {
+			break
+		}
+		p.pos++
+	}
+	num := p.s[start:p.pos]
+	if decimal {
+		if strings.HasSuffix(num, ".") {
+			return nil, p.errorf("decimal can't end with '.'")
+		}
+		whole, frac, _ := strings.Cut(strings.TrimPrefix(num, "-"), ".")
+		if len(whole) > 12 || len(frac) > 3 {
+			return nil, &SyntaxError{Pos: start, Msg: "invalid decimal " + num + ", at most 12 integer and 3 fractional digits"}
+		}
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return nil, &SyntaxError{Pos: start, Msg: "invalid decimal " + num}
+		}
+		return f, nil
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || len(strings.TrimPrefix(num, "-")) > 15 {
+		return nil, &SyntaxError{Pos: start, Msg: "invalid integer " + num}
+	}
+	return n, nil
+}
+
+func (p *sfParser) str() (interface{}, error) {
+	p.pos++ // opening quote
+	var b strings.Builder
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == '\\':
+			p.pos++
+			if n := p.peek(); n != '"' && n != '\\' {
+				return nil, p.errorf("invalid escape in string")
+			}
+			b.WriteByte(p.peek())
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c < 0x20 || c > 0x7e:
+			return nil, p.errorf("invalid character %q in string", c)
+		default:
+			b.WriteByte(c)
+		}
+		p.pos++
+	}
+	return nil, p.errorf("unterminated string")
+}
+
+func (p *sfParser) token() SFToken {
+	start := p.pos
+	p.pos++
+	for !p.eof() {
+		c := p.peek()
+		if !isTchar(c) && c != ':' && c != '/' {
+			break
+		}
+		p.pos++
+	}
+	return SFToken(p.s[start:p.pos])
+}
+
+func (p *sfParser) bytes() (interface{}, error) {
+	p.pos++ // ':'
+	end := strings.IndexByte(p.s[p.pos:], ':')
+	if end < 0 {
+		return nil, p.errorf("unterminated byte sequence")
+	}
+	raw := p.s[p.pos : p.pos+end]
+	b, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, p.errorf("invalid base64 in byte sequence")
+	}
+	p.pos += end + 1
+	return b, nil
+}
+
+// isTchar reports whether c is a token character (RFC 9110 §5.6.2).
+func isTchar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
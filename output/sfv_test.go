@@ -0,0 +1,142 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSFItem(t *testing.T) {
+	tests := []struct {
+		in   string
+		want SFItem
+	}{
+		{"same-origin", SFItem{Value: SFToken("same-origin")}},
+		{" require-corp", SFItem{Value: SFToken("require-corp")}},
+		{`same-origin; report-to="coop"`, SFItem{Value: SFToken("same-origin"), Params: []SFParam{{Key: "report-to", Value: "coop"}}}},
+		{"a;b;c=?0", SFItem{Value: SFToken("a"), Params: []SFParam{{Key: "b", Value: true}, {Key: "c", Value: false}}}},
+		{`"a \"quoted\" \\ string"`, SFItem{Value: `a "quoted" \ string`}},
+		{"?1", SFItem{Value: true}},
+		{"?0", SFItem{Value: false}},
+		{"42", SFItem{Value: int64(42)}},
+		{"-3.14", SFItem{Value: -3.14}},
+		{"123456789012.123", SFItem{Value: 123456789012.123}},
+		{"999999999999999", SFItem{Value: int64(999999999999999)}},
+		{":aGVsbG8=:", SFItem{Value: []byte("hello")}},
+		{"*/*", SFItem{Value: SFToken("*/*")}},
+		{"foo:bar/baz", SFItem{Value: SFToken("foo:bar/baz")}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSFItem(tt.in)
+		if err != nil {
+			t.Errorf("ParseSFItem(%q) = %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSFItem(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseSFItemInvalid(t *testing.T) {
+	tests := []struct {
+		in  string
+		pos int
+	}{
+		{"", 0},
+		{"1.", 2},
+		{"?2", 1},
+		{"1234567890123456", 0},
+		{"1.234.5", 5},
+		{"1.2345", 0},
+		{"1234567890123.5", 0},
+		{`"a\qb"`, 3},
+		{`"abc`, 4},
+		{"\"tab\there\"", 4},
+		{":aGVsbG8", 1},
+		{":not base64!:", 1},
+		{"same-origin extra", 12},
+		{"same-origin;", 12},
+		{"same-origin;Report-To", 12},
+		{"(a b)", 0},
+		{"-", 1},
+	}
+	for _, tt := range tests {
+		_, err := ParseSFItem(tt.in)
+		se, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("ParseSFItem(%q) = %v, want a syntax error at %d", tt.in, err, tt.pos)
+			continue
+		}
+		if se.Pos != tt.pos {
+			t.Errorf("ParseSFItem(%q) = %v, want a syntax error at %d", tt.in, err, tt.pos)
+		}
+	}
+}
+
+func TestParseSFDictionary(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []SFMember
+	}{
+		{"", nil},
+		{"a=1, b=?0, c", []SFMember{
+			{Key: "a", Item: SFItem{Value: int64(1)}},
+			{Key: "b", Item: SFItem{Value: false}},
+			{Key: "c", Item: SFItem{Value: true}},
+		}},
+		{`geolocation=(), camera=(self "https://a.example");report-to=pp`, []SFMember{
+			{Key: "geolocation", IsList: true},
+			{Key: "camera", IsList: true, List: []SFItem{{Value: SFToken("self")}, {Value: "https://a.example"}},
+				Params: []SFParam{{Key: "report-to", Value: SFToken("pp")}}},
+		}},
+		{"fullscreen=*", []SFMember{{Key: "fullscreen", Item: SFItem{Value: SFToken("*")}}}},
+		{"a;x=1,\tb", []SFMember{
+			{Key: "a", Item: SFItem{Value: true, Params: []SFParam{{Key: "x", Value: int64(1)}}}},
+			{Key: "b", Item: SFItem{Value: true}},
+		}},
+		// A repeated key keeps its position and takes the last value.
+		{"a=1, b=2, a=3", []SFMember{
+			{Key: "a", Item: SFItem{Value: int64(3)}},
+			{Key: "b", Item: SFItem{Value: int64(2)}},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSFDictionary(tt.in)
+		if err != nil {
+			t.Errorf("ParseSFDictionary(%q) = %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSFDictionary(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseSFDictionaryInvalid(t *testing.T) {
+	tests := []struct {
+		in  string
+		pos int
+	}{
+		{"a=1,", 4},
+		{"a=1 b=2", 4},
+		{"a=1;", 4},
+		{"A=1", 0},
+		{"1=a", 0},
+		{"a=(1", 4},
+		{"a=(1,2)", 4},
+		{"a=(1 2", 6},
+		{"a=", 2},
+		{"camera=(“self”)", 8},
+	}
+	for _, tt := range tests {
+		_, err := ParseSFDictionary(tt.in)
+		se, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("ParseSFDictionary(%q) = %v, want a syntax error at %d", tt.in, err, tt.pos)
+			continue
+		}
+		if se.Pos != tt.pos {
+			t.Errorf("ParseSFDictionary(%q) = %v, want a syntax error at %d", tt.in, err, tt.pos)
+		}
+	}
+}
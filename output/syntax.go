@@ -0,0 +1,281 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// StatusInvalid marks a recommended header whose value browsers can't parse.
+const StatusInvalid = "invalid"
+
+// syntaxCheck validates the grammar of a header value and returns where it
+// stops being parseable, or nil. It runs before the semantic recCheck.
+type syntaxCheck func(val string) *SyntaxError
+
+var recSyntax = map[string]syntaxCheck{
+	"Strict-Transport-Security":         func(v string) *SyntaxError { return directiveList(v, ';') },
+	"Cache-Control":                     func(v string) *SyntaxError { return directiveList(v, ',') },
+	"X-Content-Type-Options":            singleToken,
+	"X-Permitted-Cross-Domain-Policies": singleToken,
+	"Referrer-Policy":                   tokenList,
+	"Content-Type":                      mediaType,
+	"Content-Security-Policy":           serializedCSP,
+	"Clear-Site-Data":                   stringList,
+	"Permissions-Policy":                sfDictionary,
+}
+
+// syntaxErrorAt describes the character at pos, spelling out non-ASCII
+// characters such as smart quotes that are easy to miss.
+func syntaxErrorAt(s string, pos int, expected string) *SyntaxError {
+	if pos >= len(s) {
+		return &SyntaxError{Pos: pos, Msg: "unexpected end of value, " + expected}
+	}
+	if s[pos] >= utf8.RuneSelf {
+		r, _ := utf8.DecodeRuneInString(s[pos:])
+		return &SyntaxError{Pos: pos, Msg: fmt.Sprintf("non-ASCII character %q (U+%04X), %s", r, r, expected)}
+	}
+	return &SyntaxError{Pos: pos, Msg: fmt.Sprintf("unexpected %q, %s", s[pos], expected)}
+}
+
+func skipWS(s string, pos int) int {
+	for pos < len(s) && (s[pos] == ' ' || s[pos] == '\t') {
+		pos++
+	}
+	return pos
+}
+
+// scanToken returns the end of the token starting at pos (RFC 9110 §5.6.2).
+func scanToken(s string, pos int) int {
+	for pos < len(s) && isTchar(s[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// scanQuoted returns the end of the quoted-string starting at pos
+// (RFC 9110 §5.6.4).
+func scanQuoted(s string, pos int) (int, *SyntaxError) {
+	for i := pos + 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			return i + 1, nil
+		case c == '\\':
+			i++
+		case c < 0x20 && c != '\t', c == 0x7f:
+			return 0, syntaxErrorAt(s, i, "control characters are not allowed in a quoted string")
+		}
+	}
+	return 0, &SyntaxError{Pos: pos, Msg: "unterminated quoted string"}
+}
+
+// scanValue consumes a token or quoted-string.
+func scanValue(s string, pos int) (int, *SyntaxError) {
+	if pos < len(s) && s[pos] == '"' {
+		return scanQuoted(s, pos)
+	}
+	end := scanToken(s, pos)
+	if end == pos {
+		return 0, syntaxErrorAt(s, pos, "expected a token or quoted string")
+	}
+	return end, nil
+}
+
+// directiveList validates name[=value] directives separated by sep, as in
+// Strict-Transport-Security (RFC 6797) and Cache-Control (RFC 9111). Empty
+// elements are allowed by both grammars.
+func directiveList(s string, sep byte) *SyntaxError {
+	pos := 0
+	for {
+		pos = skipWS(s, pos)
+		if pos == len(s) {
+			return nil
+		}
+		if s[pos] == sep {
+			pos++
+			continue
+		}
+		end := scanToken(s, pos)
+		if end == pos {
+			return syntaxErrorAt(s, pos, "expected a directive name")
+		}
+		pos = skipWS(s, end)
+		if pos < len(s) && s[pos] == '=' {
+			var err *SyntaxError
+			if pos, err = scanValue(s, skipWS(s, pos+1)); err != nil {
+				return err
+			}
+			pos = skipWS(s, pos)
+		}
+		if pos < len(s) && s[pos] != sep {
+			return syntaxErrorAt(s, pos, fmt.Sprintf("expected %q between directives", sep))
+		}
+	}
+}
+
+// singleToken validates a header made of one token, such as nosniff.
+func singleToken(s string) *SyntaxError {
+	pos := skipWS(s, 0)
+	end := scanToken(s, pos)
+	if end == pos {
+		return syntaxErrorAt(s, pos, "expected a token")
+	}
+	if end = skipWS(s, end); end < len(s) {
+		return syntaxErrorAt(s, end, "expected a single token")
+	}
+	return nil
+}
+
+// list validates a comma-separated list whose elements are parsed by elem.
+func list(s string, elem func(pos int) (int, *SyntaxError)) *SyntaxError {
+	pos := 0
+	for {
+		pos = skipWS(s, pos)
+		if pos == len(s) {
+			return nil
+		}
+		if s[pos] == ',' {
+			pos++
+			continue
+		}
+		var err *SyntaxError
+		if pos, err = elem(pos); err != nil {
+			return err
+		}
+		if pos = skipWS(s, pos); pos < len(s) && s[pos] != ',' {
+			return syntaxErrorAt(s, pos, "expected ',' between list members")
+		}
+	}
+}
+
+func tokenList(s string) *SyntaxError {
+	return list(s, func(pos int) (int, *SyntaxError) {
+		end := scanToken(s, pos)
+		if end == pos {
+			return 0, syntaxErrorAt(s, pos, "expected a token")
+		}
+		return end, nil
+	})
+}
+
+func stringList(s string) *SyntaxError {
+	return list(s, func(pos int) (int, *SyntaxError) { return scanValue(s, pos) })
+}
+
+// mediaType validates type "/" subtype *( OWS ";" OWS [ parameter ] )
+// (RFC 9110 §8.3.1).
+func mediaType(s string) *SyntaxError {
+	pos := skipWS(s, 0)
+	for i, expected := range []string{"expected a type", "expected a subtype"} {
+		end := scanToken(s, pos)
+		if end == pos {
+			return syntaxErrorAt(s, pos, expected)
+		}
+		pos = end
+		if i == 0 {
+			if pos >= len(s) || s[pos] != '/' {
+				return syntaxErrorAt(s, pos, "expected '/' between type and subtype")
+			}
+			pos++
+		}
+	}
+	for {
+		pos = skipWS(s, pos)
+		if pos == len(s) {
+			return nil
+		}
+		if s[pos] != ';' {
+			return syntaxErrorAt(s, pos, "expected ';' before a parameter")
+		}
+		pos = skipWS(s, pos+1)
+		if pos == len(s) || s[pos] == ';' {
+			continue
+		}
+		end := scanToken(s, pos)
+		if end == pos {
+			return syntaxErrorAt(s, pos, "expected a parameter name")
+		}
+		if pos = end; pos >= len(s) || s[pos] != '=' {
+			return syntaxErrorAt(s, pos, "expected '=' after the parameter name")
+		}
+		var err *SyntaxError
+		if pos, err = scanValue(s, pos+1); err != nil {
+			return err
+		}
+	}
+}
+
+// serializedCSP validates a policy list (CSP3 §2.2.1): directive names are
+// ALPHA / DIGIT / "-", values are ASCII visible characters other than ';'.
+// Commas separate policies.
+func serializedCSP(s string) *SyntaxError {
+	atName := true
+	for pos := 0; pos < len(s); pos++ {
+		c := s[pos]
+		switch {
+		case c == ';' || c == ',':
+			atName = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\f' || c == '\r':
+			if atName {
+				continue
+			}
+		case c >= utf8.RuneSelf || c < 0x20 || c == 0x7f:
+			return syntaxErrorAt(s, pos, "directives may only contain visible ASCII characters")
+		case atName:
+			end := pos
+			for end < len(s) && (s[end] == '-' || s[end] >= 'a' && s[end] <= 'z' || s[end] >= 'A' && s[end] <= 'Z' || s[end] >= '0' && s[end] <= '9') {
+				end++
+			}
+			if end == pos {
+				return syntaxErrorAt(s, pos, "expected a directive name")
+			}
+			if end < len(s) && s[end] != ' ' && s[end] != '\t' && s[end] != ';' && s[end] != ',' {
+				return syntaxErrorAt(s, end, "expected whitespace after the directive name")
+			}
+			pos, atName = end-1, false
+		}
+	}
+	return nil
+}
+
+func sfDictionary(s string) *SyntaxError {
+	_, err := ParseSFDictionary(s)
+	var se *SyntaxError
+	if errors.As(err, &se) {
+		return se
+	}
+	return nil
+}
+
+// syntaxContext returns the part of val around the error position, with the
+// offending character marked.
+func syntaxContext(val string, pos int) string {
+	if pos >= len(val) {
+		return val + "»«"
+	}
+	start, end := pos-20, pos+20
+	if start < 0 {
+		start = 0
+	}
+	_, size := utf8.DecodeRuneInString(val[pos:])
+	if end < pos+size {
+		end = pos + size
+	}
+	if end > len(val) {
+		end = len(val)
+	}
+	for start > 0 && !utf8.RuneStart(val[start]) {
+		start--
+	}
+	for end < len(val) && !utf8.RuneStart(val[end]) {
+		end++
+	}
+	out := val[start:pos] + "»" + val[pos:pos+size] + "«" + val[pos+size:end]
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(val) {
+		out += "…"
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package output
+
+import "testing"
+
+func TestSyntaxChecks(t *testing.T) {
+	hsts := recSyntax["Strict-Transport-Security"]
+	cacheControl := recSyntax["Cache-Control"]
+
+	// pos is the expected error position, -1 when the value is valid.
+	tests := []struct {
+		name  string
+		check syntaxCheck
+		in    string
+		pos   int
+	}{
+		{"hsts", hsts, "max-age=31536000; includeSubDomains", -1},
+		{"hsts", hsts, "max-age=31536000;; preload;", -1},
+		{"hsts", hsts, `max-age="31536000"`, -1},
+		{"hsts", hsts, "max-age = 31536000", -1},
+		{"hsts", hsts, "", -1},
+		{"hsts", hsts, "max-age=", 8},
+		{"hsts", hsts, "max-age=1 includeSubDomains", 10},
+		{"hsts", hsts, "max-age=“1”", 8},
+		{"hsts", hsts, "=1", 0},
+		{"hsts", hsts, `max-age="1`, 8},
+		{"hsts", hsts, "max-age=\"1\x01\"", 10},
+		{"cache-control", cacheControl, "no-store, max-age=0", -1},
+		{"cache-control", cacheControl, "no-store; max-age=0", 8},
+
+		{"single token", singleToken, "nosniff", -1},
+		{"single token", singleToken, " nosniff ", -1},
+		{"single token", singleToken, "", 0},
+		{"single token", singleToken, "nosniff, nosniff", 7},
+		{"single token", singleToken, "no sniff", 3},
+
+		{"token list", tokenList, "no-referrer, strict-origin", -1},
+		{"token list", tokenList, "no-referrer,,strict-origin,", -1},
+		{"token list", tokenList, "no-referrer strict-origin", 12},
+		{"token list", tokenList, `"no-referrer"`, 0},
+
+		{"string list", stringList, `"cache", "cookies"`, -1},
+		{"string list", stringList, `"*"`, -1},
+		{"string list", stringList, `cache`, -1},
+		{"string list", stringList, `"cache" "cookies"`, 8},
+		{"string list", stringList, `"cache`, 0},
+
+		{"media type", mediaType, "text/html; charset=utf-8", -1},
+		{"media type", mediaType, "text/html;", -1},
+		{"media type", mediaType, `text/html; charset="utf-8"`, -1},
+		{"media type", mediaType, "text/html;;charset=utf-8", -1},
+		{"media type", mediaType, "text", 4},
+		{"media type", mediaType, "text/", 5},
+		{"media type", mediaType, "/html", 0},
+		{"media type", mediaType, "text/html charset=utf-8", 10},
+		{"media type", mediaType, "text/html; charset", 18},
+		{"media type", mediaType, "text/html; charset =utf-8", 18},
+		{"media type", mediaType, "text/html; charset=", 19},
+
+		{"csp", serializedCSP, "default-src 'self'; script-src 'none'", -1},
+		{"csp", serializedCSP, "default-src 'self', script-src 'none'", -1},
+		{"csp", serializedCSP, " ; upgrade-insecure-requests;", -1},
+		{"csp", serializedCSP, "", -1},
+		{"csp", serializedCSP, "default-src ‘self’", 12},
+		{"csp", serializedCSP, "default_src 'self'", 7},
+		{"csp", serializedCSP, "'self'", 0},
+		{"csp", serializedCSP, "script-src 'self'\x01", 17},
+
+		{"sf dictionary", sfDictionary, "geolocation=(), camera=(self)", -1},
+		{"sf dictionary", sfDictionary, `camera=(self "https://a.example")`, -1},
+		{"sf dictionary", sfDictionary, "geolocation=(),", 15},
+		{"sf dictionary", sfDictionary, "Geolocation=()", 0},
+		{"sf dictionary", sfDictionary, "camera=(self", 12},
+	}
+	for _, tt := range tests {
+		err := tt.check(tt.in)
+		switch {
+		case tt.pos < 0 && err != nil:
+			t.Errorf("%s(%q) = %v, want valid", tt.name, tt.in, err)
+		case tt.pos >= 0 && err == nil:
+			t.Errorf("%s(%q) is valid, want an error at %d", tt.name, tt.in, tt.pos)
+		case tt.pos >= 0 && err.Pos != tt.pos:
+			t.Errorf("%s(%q) = %v, want an error at %d", tt.name, tt.in, err, tt.pos)
+		}
+	}
+}
+
+func TestSyntaxErrorAt(t *testing.T) {
+	tests := []struct {
+		in   string
+		pos  int
+		want string
+	}{
+		{"a;b", 1, `unexpected ';', expected x`},
+		{"ab", 2, "unexpected end of value, expected x"},
+		{"a“b", 1, `non-ASCII character '“' (U+201C), expected x`},
+	}
+	for _, tt := range tests {
+		if got := syntaxErrorAt(tt.in, tt.pos, "expected x").Msg; got != tt.want {
+			t.Errorf("syntaxErrorAt(%q, %d) = %q, want %q", tt.in, tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestSyntaxContext(t *testing.T) {
+	tests := []struct {
+		in   string
+		pos  int
+		want string
+	}{
+		{"abc", 1, "a»b«c"},
+		{"abc", 3, "abc»«"},
+		{"a“b", 1, "a»“«b"},
+		{"0123456789012345678901234567890123456789012345", 25, "…56789012345678901234»5«6789012345678901234…"},
+	}
+	for _, tt := range tests {
+		if got := syntaxContext(tt.in, tt.pos); got != tt.want {
+			t.Errorf("syntaxContext(%q, %d) = %q, want %q", tt.in, tt.pos, got, tt.want)
+		}
+	}
+}
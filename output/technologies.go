@@ -0,0 +1,41 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Technology is a component of the target's stack identified from its
+// headers and cookies. Confidence is a percentage.
+type Technology struct {
+	Name       string   `json:"name"`
+	Category   string   `json:"category"`
+	Version    string   `json:"version,omitempty"`
+	Confidence int      `json:"confidence"`
+	Evidence   []string `json:"evidence,omitempty"`
+}
+
+func technologiesCLI(techs []Technology) {
+	section("Technologies")
+	if len(techs) == 0 {
+		fmt.Printf(" %s %s None identified\n\n", "└─", green("["+tick+"]"))
+		return
+	}
+	for idx, t := range techs {
+		last := idx == len(techs)-1
+		branch, vert := "├─", "│"
+		if last {
+			branch, vert = "└─", " "
+		}
+		fmt.Printf(" %s %s (%s, %d%%)\n", branch, strings.TrimSpace(t.Name+" "+t.Version), t.Category, t.Confidence)
+		if ShowRecommendedDetails {
+			for _, e := range t.Evidence {
+				fmt.Printf(" %s  → %s\n", vert, e)
+			}
+		}
+		if !last {
+			fmt.Println(" │")
+		}
+	}
+	fmt.Println()
+}
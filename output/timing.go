@@ -0,0 +1,176 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ServerTimingMetric is one metric of a Server-Timing header.
+type ServerTimingMetric struct {
+	Name        string   `json:"name"`
+	Duration    *float64 `json:"duration,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+func (m ServerTimingMetric) String() string {
+	var params []string
+	if m.Duration != nil {
+		params = append(params, "dur="+strconv.FormatFloat(*m.Duration, 'f', -1, 64))
+	}
+	if m.Description != "" {
+		params = append(params, strconv.Quote(m.Description))
+	}
+	if len(params) == 0 {
+		return m.Name
+	}
+	return m.Name + " (" + strings.Join(params, ", ") + ")"
+}
+
+var (
+	// backendMetric matches metric names revealing the components behind
+	// the application.
+	backendMetric = regexp.MustCompile(`(?i)\b(?:db|sql|mysql|postgres\w*|pg|mongo\w*|redis|memcache\w*|elastic\w*|solr|query|queries|upstream|origin|backend|cache|php|rails|django|laravel|lambda|rds)\b`)
+	// queryDescription matches descriptions that carry a database query.
+	queryDescription = regexp.MustCompile(`(?i)\b(?:select\b.+\bfrom|insert\s+into|update\b.+\bset|delete\s+from)\b`)
+)
+
+// ParseServerTiming parses a Server-Timing value (Server Timing §3): metric
+// names, each followed by ;-separated dur and desc parameters. Malformed
+// parameters are skipped as browsers do.
+func ParseServerTiming(raw string) []ServerTimingMetric {
+	var out []ServerTimingMetric
+	pos := 0
+	for pos < len(raw) {
+		pos = skipWS(raw, pos)
+		if pos < len(raw) && raw[pos] == ',' {
+			pos++
+			continue
+		}
+		end := scanToken(raw, pos)
+		if end == pos {
+			// Skip to the next metric.
+			for pos < len(raw) && raw[pos] != ',' {
+				pos++
+			}
+			continue
+		}
+		m := ServerTimingMetric{Name: raw[pos:end]}
+		pos = end
+		for {
+			pos = skipWS(raw, pos)
+			if pos >= len(raw) || raw[pos] != ';' {
+				break
+			}
+			pos = skipWS(raw, pos+1)
+			end := scanToken(raw, pos)
+			name := strings.ToLower(raw[pos:end])
+			pos = skipWS(raw, end)
+			if pos >= len(raw) || raw[pos] != '=' {
+				continue
+			}
+			start := skipWS(raw, pos+1)
+			end, err := scanValue(raw, start)
+			if err != nil {
+				// Skip the value, keeping the separator that follows.
+				pos = start
+				for pos < len(raw) && raw[pos] != ';' && raw[pos] != ',' {
+					pos++
+				}
+				continue
+			}
+			val := raw[start:end]
+			if strings.HasPrefix(val, `"`) {
+				val, _ = strconv.Unquote(val)
+			}
+			pos = end
+			switch name {
+			case "dur":
+				if d, err := strconv.ParseFloat(val, 64); err == nil && m.Duration == nil {
+					m.Duration = &d
+				}
+			case "desc":
+				if m.Description == "" {
+					m.Description = val
+				}
+			}
+		}
+		out = append(out, m)
+		for pos < len(raw) && raw[pos] != ',' {
+			pos++
+		}
+	}
+	return out
+}
+
+// timingAllowsAll reports whether Timing-Allow-Origin exposes resource
+// timing to every origin.
+func timingAllowsAll(values []string) bool {
+	for _, v := range values {
+		for _, o := range strings.Split(v, ",") {
+			if strings.TrimSpace(o) == "*" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serverTimingFinding lists the metrics a Server-Timing header exposes. It
+// is rated by what the metrics reveal, one level higher when a wildcard
+// Timing-Allow-Origin lets any origin read them.
+func serverTimingFinding(values []string, allowAll bool) (LeakFinding, bool) {
+	raw := strings.TrimSpace(strings.Join(values, ", "))
+	metrics := ParseServerTiming(raw)
+	if len(metrics) == 0 {
+		return LeakFinding{}, false
+	}
+	lf := LeakFinding{Header: "Server-Timing", Value: raw, Rule: "timing/server-timing", Category: "timing", Severity: "info", Metrics: metrics}
+	for _, m := range metrics {
+		switch {
+		case queryDescription.MatchString(m.Description):
+			lf.Severity, lf.Match = "medium", m.Name
+		case lf.Severity == "info" && (m.Description != "" || backendMetric.MatchString(m.Name)):
+			lf.Severity = "low"
+		}
+	}
+	if allowAll {
+		lf.Severity = map[string]string{"info": "low", "low": "medium", "medium": "high"}[lf.Severity]
+	}
+	return lf, true
+}
+
+// timingAllowOriginFinding reports which origins may read resource timing.
+func timingAllowOriginFinding(values []string) (LeakFinding, bool) {
+	raw := strings.TrimSpace(strings.Join(values, ", "))
+	if raw == "" {
+		return LeakFinding{}, false
+	}
+	lf := LeakFinding{Header: "Timing-Allow-Origin", Value: raw, Rule: "timing/timing-allow-origin", Category: "timing", Severity: "info"}
+	switch {
+	case timingAllowsAll(values):
+		lf.Severity, lf.Match = "low", "*"
+	case strings.Contains(strings.ToLower(raw), "null"):
+		lf.Severity, lf.Match = "low", "null"
+	}
+	return lf, true
+}
+
+// timingDetail describes a timing finding for the CLI.
+func timingDetail(lf LeakFinding) string {
+	if lf.Header == "Timing-Allow-Origin" {
+		switch lf.Match {
+		case "*":
+			return "resource timing and Server-Timing are readable by any origin"
+		case "null":
+			return "resource timing is readable by opaque (sandboxed) origins"
+		}
+		return "resource timing is readable by the listed origins"
+	}
+	detail := fmt.Sprintf("%d metric(s) exposed", len(lf.Metrics))
+	if lf.Match != "" {
+		detail += ", " + lf.Match + " describes a database query"
+	}
+	return detail
+}
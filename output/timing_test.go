@@ -0,0 +1,83 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseServerTiming(t *testing.T) {
+	dur := func(d float64) *float64 { return &d }
+	tests := []struct {
+		in   string
+		want []ServerTimingMetric
+	}{
+		{"db;dur=53, app;dur=47.2", []ServerTimingMetric{{Name: "db", Duration: dur(53)}, {Name: "app", Duration: dur(47.2)}}},
+		{`cache;desc="Cache Read";dur=23.2`, []ServerTimingMetric{{Name: "cache", Duration: dur(23.2), Description: "Cache Read"}}},
+		{"miss, db;dur=53", []ServerTimingMetric{{Name: "miss"}, {Name: "db", Duration: dur(53)}}},
+		{"total ; dur = 123.4 ; desc=Total", []ServerTimingMetric{{Name: "total", Duration: dur(123.4), Description: "Total"}}},
+		{"db;DUR=5", []ServerTimingMetric{{Name: "db", Duration: dur(5)}}},
+		{`q;desc="a \"quoted\" query"`, []ServerTimingMetric{{Name: "q", Description: `a "quoted" query`}}},
+		// Malformed parameters are skipped, the metric is kept.
+		{"db;dur=abc", []ServerTimingMetric{{Name: "db"}}},
+		{"db;dur", []ServerTimingMetric{{Name: "db"}}},
+		{"db;dur=1;dur=2", []ServerTimingMetric{{Name: "db", Duration: dur(1)}}},
+		{"db;dur=;desc=x", []ServerTimingMetric{{Name: "db", Description: "x"}}},
+		{`db;desc="unterminated`, []ServerTimingMetric{{Name: "db"}}},
+		{"db;foo=bar;dur=3", []ServerTimingMetric{{Name: "db", Duration: dur(3)}}},
+		{"db garbage;dur=1, app", []ServerTimingMetric{{Name: "db"}, {Name: "app"}}},
+		// Metrics without a valid name are dropped.
+		{";dur=1, db", []ServerTimingMetric{{Name: "db"}}},
+		{`"quoted";dur=1`, nil},
+		{"", nil},
+		{" , ,", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseServerTiming(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseServerTiming(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestServerTimingFinding(t *testing.T) {
+	tests := []struct {
+		values   []string
+		allowAll bool
+		severity string
+		match    string
+	}{
+		{[]string{"total;dur=12"}, false, "info", ""},
+		{[]string{"total;dur=12"}, true, "low", ""},
+		{[]string{"mysql;dur=3"}, false, "low", ""},
+		{[]string{"app;desc=render"}, false, "low", ""},
+		{[]string{`q;desc="SELECT * FROM users WHERE id=1"`}, false, "medium", "q"},
+		{[]string{"total;dur=1", `q;desc="delete from sessions"`}, true, "high", "q"},
+	}
+	for _, tt := range tests {
+		lf, ok := serverTimingFinding(tt.values, tt.allowAll)
+		if !ok || lf.Severity != tt.severity || lf.Match != tt.match {
+			t.Errorf("serverTimingFinding(%q, %v) = %s %q, want %s %q", tt.values, tt.allowAll, lf.Severity, lf.Match, tt.severity, tt.match)
+		}
+	}
+	if _, ok := serverTimingFinding([]string{";;"}, false); ok {
+		t.Error("serverTimingFinding without metrics reported a finding")
+	}
+}
+
+func TestTimingAllowsAll(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   bool
+	}{
+		{[]string{"*"}, true},
+		{[]string{"https://a.example, *"}, true},
+		{[]string{"https://a.example", " * "}, true},
+		{[]string{"https://a.example"}, false},
+		{[]string{"null"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := timingAllowsAll(tt.values); got != tt.want {
+			t.Errorf("timingAllowsAll(%q) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package output
+
+import "fmt"
+
+// Vulnerability is a known CVE affecting a product version disclosed by a
+// leak header. Confidence is low when the CVE lists every version of the
+// product or only applies in combination with another product.
+type Vulnerability struct {
+	Header     string  `json:"header"`
+	Product    string  `json:"product"`
+	Version    string  `json:"version"`
+	ID         string  `json:"id"`
+	Severity   string  `json:"severity,omitempty"`
+	Score      float64 `json:"score,omitempty"`
+	Confidence string  `json:"confidence"`
+}
+
+// maxVulnsCLI caps the CVEs listed per product; the JSON output has all.
+const maxVulnsCLI = 10
+
+func vulnsCLI(vulns []Vulnerability) {
+	section("Known Vulnerabilities (offline database)")
+	if len(vulns) == 0 {
+		fmt.Printf(" %s %s None found for the disclosed versions\n\n", "└─", green("["+tick+"]"))
+		return
+	}
+
+	// Vulnerabilities arrive grouped by header and product.
+	type group struct {
+		title string
+		vulns []Vulnerability
+	}
+	var groups []group
+	for _, v := range vulns {
+		title := fmt.Sprintf("%s %s (%s)", v.Product, v.Version, v.Header)
+		if n := len(groups); n == 0 || groups[n-1].title != title {
+			groups = append(groups, group{title: title})
+		}
+		groups[len(groups)-1].vulns = append(groups[len(groups)-1].vulns, v)
+	}
+
+	for idx, g := range groups {
+		last := idx == len(groups)-1
+		branch, vert := "├─", "│"
+		if last {
+			branch, vert = "└─", " "
+		}
+		fmt.Printf(" %s %s %s: %d CVE(s)\n", branch, red("["+cross+"]"), g.title, len(g.vulns))
+		for i, v := range g.vulns {
+			if i == maxVulnsCLI {
+				fmt.Printf(" %s  → … and %d more (see the JSON output)\n", vert, len(g.vulns)-maxVulnsCLI)
+				break
+			}
+			rating := v.Severity
+			if v.Score > 0 {
+				rating = fmt.Sprintf("%s %.1f", v.Severity, v.Score)
+			}
+			note := ""
+			if v.Confidence == "low" {
+				note = " (low confidence: all versions or a combined configuration)"
+			}
+			fmt.Printf(" %s  → [%s] %s%s\n", vert, rating, v.ID, note)
+		}
+		if !last {
+			fmt.Println(" │")
+		}
+	}
+	fmt.Println()
+}
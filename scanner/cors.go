@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/andrealungh1/HeaderSec/output"
+)
+
+// probeDomain is the attacker-controlled domain used to build probe origins.
+const probeDomain = "evil-headersec.com"
+
+type corsProbe struct {
+	name   string
+	origin string
+	// severity of a reflection with and without credentials
+	withCreds, withoutCreds string
+}
+
+func corsProbes(target *url.URL) []corsProbe {
+	host := target.Hostname()
+	probes := []corsProbe{
+		{"arbitrary origin", "https://" + probeDomain, "high", "low"},
+		{"null origin", "null", "high", "low"},
+		{"suffix match", "https://" + host + "." + probeDomain, "high", "low"},
+		{"prefix match", "https://evil" + host, "high", "low"},
+		{"subdomain", "https://evil." + host, "low", "info"},
+	}
+	if target.Scheme == "https" {
+		probes = append(probes, corsProbe{"scheme downgrade", "http://" + target.Host, "medium", "info"})
+	}
+	// An unescaped dot in a regular expression such as ^www.example.com$
+	// also matches wwwxexample.com.
+	if i := strings.IndexByte(host, '.'); i > 0 && strings.Count(host, ".") > 1 {
+		probes = append(probes, corsProbe{"unescaped dot", "https://" + host[:i] + "x" + host[i+1:], "high", "low"})
+	}
+	return probes
+}
+
+// probeCORS re-sends the configured request with crafted Origin values and
+// records which ones the server reflects.
+func probeCORS(client *http.Client, target *url.URL, cfg Config) []output.CORSProbe {
+	var out []output.CORSProbe
+	for _, p := range corsProbes(target) {
+		res := output.CORSProbe{Name: p.name, Origin: p.origin}
+
+		req, err := http.NewRequest(cfg.Method, target.String(), nil)
+		if err != nil {
+			res.Error = err.Error()
+			out = append(out, res)
+			continue
+		}
+		setHeaders(req, cfg)
+		req.Header.Set("Origin", p.origin)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			res.Error = err.Error()
+			out = append(out, res)
+			continue
+		}
+		resp.Body.Close()
+
+		res.Status = resp.StatusCode
+		res.Headers = output.RedactHeader(resp.Header)
+		res.AllowOrigin = res.Headers.Get("Access-Control-Allow-Origin")
+		res.AllowCredentials = resp.Header.Get("Access-Control-Allow-Credentials") == "true"
+		res.Reflected = resp.Header.Get("Access-Control-Allow-Origin") == p.origin
+		if res.Reflected {
+			res.Severity = p.withoutCreds
+			if res.AllowCredentials {
+				res.Severity = p.withCreds
+			}
+		}
+		out = append(out, res)
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/andrealungh1/HeaderSec/fingerprint"
+	"github.com/andrealungh1/HeaderSec/output"
+)
+
+// categoryOrder lists the stack from the edge inwards; other categories
+// sort after these.
+var categoryOrder = []string{"load-balancer", "cache", "web-server", "operating-system", "language", "framework", "cms"}
+
+func rank(category string) int {
+	for i, c := range categoryOrder {
+		if c == category {
+			return i
+		}
+	}
+	return len(categoryOrder)
+}
+
+// identify fingerprints the technologies behind resp. The result is non-nil
+// so that an empty detection is still reported.
+func identify(db *fingerprint.DB, resp *http.Response) []output.Technology {
+	out := []output.Technology{}
+	for _, m := range db.Detect(resp.Header) {
+		out = append(out, output.Technology{
+			Name:       m.Name,
+			Category:   m.Category,
+			Version:    m.Version,
+			Confidence: m.Confidence,
+			Evidence:   m.Evidence,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if ri, rj := rank(out[i].Category), rank(out[j].Category); ri != rj {
+			return ri < rj
+		}
+		return out[i].Category < out[j].Category
+	})
+	return out
+}
+
+// detectEdge reports the CDNs and WAFs whose signatures match resp.
+func detectEdge(db *fingerprint.DB, resp *http.Response) *output.EdgeReport {
+	r := &output.EdgeReport{}
+	for _, m := range db.Detect(resp.Header) {
+		t := output.Technology{Name: m.Name, Category: m.Category, Version: m.Version, Confidence: m.Confidence, Evidence: m.Evidence}
+		switch m.Category {
+		case "cdn":
+			r.CDN = append(r.CDN, t)
+		case "waf":
+			r.WAF = append(r.WAF, t)
+		}
+	}
+	return r
+}
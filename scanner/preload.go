@@ -0,0 +1,145 @@
+package scanner
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/andrealungh1/HeaderSec/output"
+)
+
+// preloadCache holds one preload verdict per registrable domain, so that
+// targets sharing an apex are only checked once.
+type preloadCache struct {
+	mu      sync.Mutex
+	results map[string]*preloadOnce
+}
+
+type preloadOnce struct {
+	once sync.Once
+	res  *output.PreloadResult
+}
+
+func newPreloadCache() *preloadCache {
+	return &preloadCache{results: map[string]*preloadOnce{}}
+}
+
+// checkPreload returns the preload verdict for the registrable domain of
+// host, computing it on first use.
+func checkPreload(client *http.Client, host string, cfg Config) *output.PreloadResult {
+	apex := output.RegistrableDomain(host)
+	if cfg.preloadCache == nil {
+		return preloadVerdict(client, apex, cfg)
+	}
+	cfg.preloadCache.mu.Lock()
+	entry, ok := cfg.preloadCache.results[apex]
+	if !ok {
+		entry = &preloadOnce{}
+		cfg.preloadCache.results[apex] = entry
+	}
+	cfg.preloadCache.mu.Unlock()
+	entry.once.Do(func() { entry.res = preloadVerdict(client, apex, cfg) })
+	return entry.res
+}
+
+// preloadVerdict verifies the hstspreload.org submission requirements for
+// apex, always on the default ports.
+func preloadVerdict(client *http.Client, apex string, cfg Config) *output.PreloadResult {
+	res := &output.PreloadResult{Host: apex}
+
+	e, ok := cfg.Preload.Lookup(apex)
+	switch {
+	case ok && e.Mode == "force-https":
+		preloaded := true
+		res.Preloaded, res.ListEntry = &preloaded, e.Name
+	case !cfg.Preload.Sample:
+		preloaded := false
+		res.Preloaded = &preloaded
+	}
+
+	if net.ParseIP(apex) != nil || !strings.Contains(apex, ".") {
+		res.Require("Registrable domain name", false, "%s can't be preloaded", apex)
+		return res
+	}
+
+	noFollow := *client
+	noFollow.CheckRedirect = func(_ *http.Request, _ []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	// HTTPS on the apex, with a certificate browsers accept.
+	resp, err := preloadGet(&noFollow, "https://"+apex+"/", cfg)
+	if err != nil {
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) {
+			res.Require("Valid certificate", false, "%v", certErr.Err)
+		} else {
+			res.Require("HTTPS on the apex domain", false, "%v", err)
+		}
+		return res
+	}
+	if cfg.Insecure {
+		res.Require("Valid certificate", true, "not verified, -insecure is set")
+	} else {
+		res.Require("Valid certificate", true, "")
+	}
+
+	// HTTP must redirect to HTTPS on the same host before going anywhere else.
+	if plain, err := preloadGet(&noFollow, "http://"+apex+"/", cfg); err != nil {
+		res.Require("Redirect from HTTP to HTTPS on the same host", true, "port 80 is not reachable (%v)", err)
+	} else {
+		passed, detail := sameHostRedirect(plain, apex)
+		res.Require("Redirect from HTTP to HTTPS on the same host", passed, "%s", detail)
+	}
+
+	// HSTS on the apex response itself, even if it is a redirect.
+	val := strings.TrimSpace(resp.Header.Get("Strict-Transport-Security"))
+	if val == "" {
+		res.Require("HSTS header on the apex domain", false, "https://%s/ answered %d without Strict-Transport-Security", apex, resp.StatusCode)
+		return res
+	}
+	h := output.ParseHSTS(val)
+	if h.Invalid {
+		res.Require("HSTS header on the apex domain", false, "%q is ignored by browsers", val)
+		return res
+	}
+	res.Require("HSTS header on the apex domain", true, "%s", val)
+	res.Require("max-age of at least one year", h.MaxAge >= 31536000, "max-age=%d", h.MaxAge)
+	res.Require("includeSubDomains directive", h.IncludeSubDomains, "")
+	res.Require("preload directive", h.Preload, "")
+	return res
+}
+
+func preloadGet(client *http.Client, rawURL string, cfg Config) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", cfg.UserAgent)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+func sameHostRedirect(resp *http.Response, host string) (bool, string) {
+	loc := resp.Header.Get("Location")
+	if resp.StatusCode < 300 || resp.StatusCode > 399 || loc == "" {
+		return false, "http://" + host + "/ answered " + resp.Status + " without redirecting"
+	}
+	target, err := resp.Request.URL.Parse(loc)
+	if err != nil {
+		return false, "invalid Location " + loc
+	}
+	if target.Scheme != "https" || !strings.EqualFold(target.Hostname(), host) {
+		return false, "redirects to " + target.String() + " instead of https://" + host + "/"
+	}
+	return true, "redirects to " + target.String()
+}
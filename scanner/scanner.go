@@ -5,25 +5,57 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/andrealungh1/HeaderSec/fingerprint"
 	"github.com/andrealungh1/HeaderSec/output"
+	"github.com/andrealungh1/HeaderSec/preload"
+	"github.com/andrealungh1/HeaderSec/vulndb"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 )
 
 type Config struct {
-	Method                               string
-	Cookie, UserAgent                    string
-	ExtraHeaders                         map[string]string
-	PortOverride                         int
-	IncludeRec, IncludeLeak, IncludeDepr bool
-	OutputJSON                           string
+	Method            string
+	Cookie, UserAgent string
+	ExtraHeaders      map[string]string
+	PortOverride      int
+	Checks            output.Checks
+	OutputJSON        string
+	Insecure          bool
+
+	// Preload enables the HSTS preload eligibility check against the
+	// given list.
+	Preload *preload.List
+	// CORSProbe re-sends the request with crafted Origin headers.
+	CORSProbe bool
+	// SniffBody sends an extra GET to sample the body when the configured
+	// method is not GET; a GET response is sampled directly.
+	SniffBody bool
+	// LogoutPatterns are matched against each segment of the URL path,
+	// LogoutTargets against the target as given, to recognise logout
+	// endpoints.
+	LogoutPatterns []*regexp.Regexp
+	LogoutTargets  map[string]bool
+	// VulnDB correlates disclosed product versions with known CVEs.
+	VulnDB *vulndb.DB
+	// Fingerprints identifies the technology stack for the leak check.
+	Fingerprints *fingerprint.DB
+	// EdgeSignatures identifies CDNs and WAFs for the edge check.
+	EdgeSignatures *fingerprint.DB
+
+	preloadCache *preloadCache
 }
 
 func Run(client *http.Client, cfg Config, targets []string, workers int) {
+	if cfg.Preload != nil {
+		cfg.preloadCache = newPreloadCache()
+	}
 
 	if cfg.OutputJSON != "" {
 		var (
@@ -40,43 +72,12 @@ func Run(client *http.Client, cfg Config, targets []string, workers int) {
 				sem <- struct{}{}
 				defer func() { <-sem }()
 
-				parsed, err := url.Parse(u)
-				if err != nil {
-					output.LogError("Invalid URL (%q): %v", u, err)
+				parsed, resp, ok := fetch(client, u, cfg)
+				if !ok {
 					return
 				}
-				if cfg.PortOverride > 0 {
-					parsed.Host = fmt.Sprintf("%s:%d", parsed.Hostname(), cfg.PortOverride)
-				}
-
-				req, _ := http.NewRequest(cfg.Method, parsed.String(), nil)
-				if cfg.Cookie != "" {
-					req.Header.Set("Cookie", cfg.Cookie)
-				}
-				if cfg.UserAgent != "" {
-					req.Header.Set("User-Agent", cfg.UserAgent)
-				}
-				for k, v := range cfg.ExtraHeaders {
-					req.Header.Set(k, v)
-				}
-
-				resp, err := client.Do(req)
-				if err != nil {
-					output.LogError("Request failed: (%s): %v", parsed, err)
-					return
-				}
-				// fallback GET se HEAD non restituisce header
-				if len(resp.Header) == 0 {
-					req.Method = http.MethodGet
-					resp.Body.Close()
-					resp, err = client.Do(req)
-					if err != nil {
-						output.LogError("GET request failed: (%s): %v", parsed, err)
-						return
-					}
-				}
-
-				data := output.ProduceJSON(parsed.String(), resp, cfg.IncludeRec, cfg.IncludeLeak, cfg.IncludeDepr)
+				ev := gather(client, u, parsed, resp, cfg)
+				data := output.ProduceJSON(parsed.String(), resp, ev, cfg.Checks)
 				resp.Body.Close()
 
 				mu.Lock()
@@ -127,10 +128,30 @@ func Run(client *http.Client, cfg Config, targets []string, workers int) {
 
 // scan – singolo URL; nessun print colorato qui dentro.
 func scan(idx int, raw string, client *http.Client, cfg Config) {
+	parsed, resp, ok := fetch(client, raw, cfg)
+	if !ok {
+		return
+	}
+	ev := gather(client, raw, parsed, resp, cfg)
+
+	if cfg.OutputJSON != "" {
+		saveJSON(idx, parsed.String(), resp, ev, cfg)
+	} else {
+		output.ProduceCLI(parsed.String(), resp, ev, cfg.Checks)
+	}
+
+	if resp.Body != nil && !errors.Is(resp.Body.Close(), http.ErrBodyReadAfterClose) {
+		_ = resp.Body.Close()
+	}
+}
+
+// fetch sends the configured request to raw, falling back to GET when the
+// response carries no headers. Errors are logged and reported as !ok.
+func fetch(client *http.Client, raw string, cfg Config) (*url.URL, *http.Response, bool) {
 	parsed, err := url.Parse(raw)
 	if err != nil {
 		output.LogError("Invalid URL (%q): %v", raw, err)
-		return
+		return nil, nil, false
 	}
 	if cfg.PortOverride > 0 {
 		parsed.Host = fmt.Sprintf("%s:%d", parsed.Hostname(), cfg.PortOverride)
@@ -139,23 +160,16 @@ func scan(idx int, raw string, client *http.Client, cfg Config) {
 	req, err := http.NewRequest(cfg.Method, parsed.String(), nil)
 	if err != nil {
 		output.LogError("Error creating request: %v", err)
-		return
-	}
-	if cfg.Cookie != "" {
-		req.Header.Set("Cookie", cfg.Cookie)
-	}
-	if cfg.UserAgent != "" {
-		req.Header.Set("User-Agent", cfg.UserAgent)
-	}
-	for k, v := range cfg.ExtraHeaders {
-		req.Header.Set(k, v)
+		return nil, nil, false
 	}
+	setHeaders(req, cfg)
 
 	resp, err := client.Do(req)
 	if err != nil {
 		output.LogError("Request failed: (%s): %v", parsed, err)
-		return
+		return nil, nil, false
 	}
+	// fallback GET se HEAD non restituisce header
 	if len(resp.Header) == 0 {
 		req.Method = http.MethodGet
 		if resp.Body != nil {
@@ -164,23 +178,107 @@ func scan(idx int, raw string, client *http.Client, cfg Config) {
 		resp, err = client.Do(req)
 		if err != nil {
 			output.LogError("GET request failed: (%s): %v", parsed, err)
-			return
+			return nil, nil, false
 		}
 	}
+	return parsed, resp, true
+}
 
-	if cfg.OutputJSON != "" {
-		saveJSON(idx, parsed.String(), resp, cfg)
-	} else {
-		output.ProduceCLI(parsed.String(), resp, cfg.IncludeRec, cfg.IncludeLeak, cfg.IncludeDepr)
+func setHeaders(req *http.Request, cfg Config) {
+	if cfg.Cookie != "" {
+		req.Header.Set("Cookie", cfg.Cookie)
 	}
+	if cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", cfg.UserAgent)
+	}
+	for k, v := range cfg.ExtraHeaders {
+		req.Header.Set(k, v)
+	}
+}
 
-	if resp.Body != nil && !errors.Is(resp.Body.Close(), http.ErrBodyReadAfterClose) {
-		_ = resp.Body.Close()
+// gather runs the active checks enabled in cfg against the target and
+// correlates resp with the offline databases. raw is the target as given by
+// the user.
+func gather(client *http.Client, raw string, target *url.URL, resp *http.Response, cfg Config) *output.Evidence {
+	ev := &output.Evidence{Logout: isLogout(raw, target, cfg)}
+	if cfg.Checks.Rec {
+		switch {
+		case resp.Request != nil && resp.Request.Method == http.MethodGet:
+			ev.Body = readSample(resp.Body)
+		case cfg.SniffBody:
+			ev.Body = sniffBody(client, target, cfg)
+		}
+	}
+	if cfg.Preload != nil {
+		ev.Preload = checkPreload(client, target.Hostname(), cfg)
+	}
+	if cfg.CORSProbe {
+		ev.CORSProbes = probeCORS(client, target, cfg)
+	}
+	// Fingerprint evidence quotes header values, so it is taken from the
+	// redacted headers.
+	clean := output.RedactResponse(resp)
+	if cfg.Fingerprints != nil && cfg.Checks.Leak {
+		ev.Technologies = identify(cfg.Fingerprints, clean)
+	}
+	if cfg.EdgeSignatures != nil && cfg.Checks.Edge {
+		ev.Edge = detectEdge(cfg.EdgeSignatures, clean)
+	}
+	if cfg.VulnDB != nil && cfg.Checks.Leak {
+		ev.Vulns = correlate(cfg.VulnDB, resp)
+	}
+	return ev
+}
+
+// sniffBody fetches the first bytes of the target's body with a GET
+// request, regardless of the configured method.
+func sniffBody(client *http.Client, target *url.URL, cfg Config) []byte {
+	req, err := http.NewRequest(http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil
+	}
+	setHeaders(req, cfg)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	return readSample(resp.Body)
+}
+
+// readSample reads the first 512 bytes of body, all that content sniffing
+// looks at.
+func readSample(body io.Reader) []byte {
+	if body == nil {
+		return nil
+	}
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(body, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil
+	}
+	return buf[:n]
+}
+
+func isLogout(raw string, target *url.URL, cfg Config) bool {
+	if cfg.LogoutTargets[raw] {
+		return true
+	}
+	for _, seg := range strings.Split(target.Path, "/") {
+		// Drop path parameters (;jsessionid=) and the file extension.
+		seg, _, _ = strings.Cut(seg, ";")
+		name := strings.TrimSuffix(seg, path.Ext(seg))
+		for _, re := range cfg.LogoutPatterns {
+			if seg != "" && (re.MatchString(seg) || re.MatchString(name)) {
+				return true
+			}
+		}
 	}
+	return false
 }
 
-func saveJSON(idx int, rawURL string, resp *http.Response, cfg Config) {
-	data := output.ProduceJSON(rawURL, resp, cfg.IncludeRec, cfg.IncludeLeak, cfg.IncludeDepr)
+func saveJSON(idx int, rawURL string, resp *http.Response, ev *output.Evidence, cfg Config) {
+	data := output.ProduceJSON(rawURL, resp, ev, cfg.Checks)
 	if cfg.OutputJSON == "-" {
 		fmt.Println(string(data))
 		return
@@ -0,0 +1,35 @@
+package scanner
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/andrealungh1/HeaderSec/output"
+	"github.com/andrealungh1/HeaderSec/vulndb"
+)
+
+// correlate looks up the product versions disclosed by leak headers in db.
+// The result is non-nil so that an empty lookup is still reported.
+func correlate(db *vulndb.DB, resp *http.Response) []output.Vulnerability {
+	out := []output.Vulnerability{}
+	for _, lf := range output.AnalyzeLeaks(resp) {
+		for _, p := range lf.Products {
+			// A major version alone matches too many ranges to be useful.
+			if lf.Disclosure != output.DisclosureExact || !strings.Contains(p.Version, ".") {
+				continue
+			}
+			for _, c := range db.Lookup(p.Name, p.Version) {
+				out = append(out, output.Vulnerability{
+					Header:     lf.Header,
+					Product:    p.Name,
+					Version:    p.Version,
+					ID:         c.ID,
+					Severity:   c.Severity,
+					Score:      c.Score,
+					Confidence: c.Confidence,
+				})
+			}
+		}
+	}
+	return out
+}
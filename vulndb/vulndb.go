@@ -0,0 +1,439 @@
+// Package vulndb correlates product versions with known CVEs using an NVD
+// JSON feed stored on disk, so that lookups work without network access.
+package vulndb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// CVE is a vulnerability record with its highest available CVSS rating.
+// Confidence is "low" when the record only matched because it lists every
+// version of the product, or because it applies to the product only in
+// combination with another one (an AND configuration), and "high" otherwise.
+type CVE struct {
+	ID         string
+	Severity   string
+	Score      float64
+	Confidence string
+}
+
+// rule is a vulnerable CPE match: either an exact version, optionally with
+// an update such as beta1, or a range. conditional rules come from AND
+// configurations, where the product is vulnerable only alongside another.
+type rule struct {
+	cve                  int
+	vendor               string
+	version, update      string
+	startIncl, startExcl string
+	endIncl, endExcl     string
+	conditional          bool
+}
+
+type DB struct {
+	cves  []CVE
+	rules map[string][]rule // keyed by CPE product
+}
+
+// aliases maps product names as servers announce them to CPE
+// vendor:product pairs. Names not listed are matched against the CPE product
+// after lowercasing and replacing spaces and dashes with underscores.
+var aliases = map[string][]string{
+	"apache":                           {"apache:http_server"},
+	"apache-coyote":                    {"apache:tomcat"},
+	"nginx":                            {"f5:nginx", "nginx:nginx"},
+	"microsoft-iis":                    {"microsoft:internet_information_services", "microsoft:iis"},
+	"asp.net":                          {"microsoft:asp.net", "microsoft:.net_framework"},
+	"asp.net mvc":                      {"microsoft:asp.net_model_view_controller", "microsoft:asp.net_mvc"},
+	"php":                              {"php:php"},
+	"openssl":                          {"openssl:openssl"},
+	"jetty":                            {"eclipse:jetty"},
+	"tomcat":                           {"apache:tomcat"},
+	"express":                          {"expressjs:express", "openjsf:express"},
+	"wordpress":                        {"wordpress:wordpress"},
+	"drupal":                           {"drupal:drupal"},
+	"joomla":                           {"joomla:joomla\\!"},
+	"joomla!":                          {"joomla:joomla\\!"},
+	"lighttpd":                         {"lighttpd:lighttpd"},
+	"openresty":                        {"openresty:openresty"},
+	"varnish":                          {"varnish-cache:varnish", "varnish_cache_project:varnish_cache"},
+	"outlook web app":                  {"microsoft:exchange_server"},
+	"umbraco":                          {"umbraco:umbraco_cms"},
+	"apache cocoon":                    {"apache:cocoon"},
+	"mod_pagespeed":                    {"google:modpagespeed"},
+	"next.js":                          {"vercel:next.js", "zeit:next.js"},
+	"oracle commerce cloud":            {"oracle:commerce_cloud"},
+	"liferay portal":                   {"liferay:liferay_portal"},
+	"phusion_passenger":                {"phusion:passenger"},
+	"litespeed":                        {"litespeedtech:litespeed_web_server"},
+	"openssh":                          {"openbsd:openssh"},
+	"mod_ssl":                          {"modssl:mod_ssl"},
+	"caddy":                            {"caddyserver:caddy"},
+	"gunicorn":                         {"gunicorn:gunicorn"},
+	"werkzeug":                         {"palletsprojects:werkzeug"},
+	"kestrel":                          {"microsoft:asp.net_core"},
+	"envoy":                            {"envoyproxy:envoy"},
+	"traefik":                          {"traefik:traefik"},
+	"jitsi meet":                       {"jitsi:jitsi_meet"},
+	"liferay community edition portal": {"liferay:liferay_portal"},
+}
+
+// Load reads an NVD feed in the legacy 1.1 format or the API 2.0 format.
+// Gzip-compressed feeds are decompressed transparently.
+func Load(path string) (*DB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Reading vulnerability database: %w", err)
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("Reading vulnerability database: %w", err)
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("Reading vulnerability database: %w", err)
+		}
+	}
+	return Parse(data)
+}
+
+type cpeMatch struct {
+	Vulnerable            bool   `json:"vulnerable"`
+	Cpe23URI              string `json:"cpe23Uri"`
+	Criteria              string `json:"criteria"`
+	VersionStartIncluding string `json:"versionStartIncluding"`
+	VersionStartExcluding string `json:"versionStartExcluding"`
+	VersionEndIncluding   string `json:"versionEndIncluding"`
+	VersionEndExcluding   string `json:"versionEndExcluding"`
+}
+
+type node struct {
+	Operator  string     `json:"operator"`
+	Negate    bool       `json:"negate"`
+	CPEMatch  []cpeMatch `json:"cpe_match"`
+	CPEMatch2 []cpeMatch `json:"cpeMatch"`
+	Children  []node     `json:"children"`
+}
+
+type cvssData struct {
+	BaseScore    float64 `json:"baseScore"`
+	BaseSeverity string  `json:"baseSeverity"`
+}
+
+type metric struct {
+	CVSSData     cvssData `json:"cvssData"`
+	BaseSeverity string   `json:"baseSeverity"`
+}
+
+type feed struct {
+	// NVD 1.1 data feeds
+	Items []struct {
+		CVE struct {
+			Meta struct {
+				ID string `json:"ID"`
+			} `json:"CVE_data_meta"`
+		} `json:"cve"`
+		Configurations struct {
+			Nodes []node `json:"nodes"`
+		} `json:"configurations"`
+		Impact struct {
+			V3 struct {
+				CVSS cvssData `json:"cvssV3"`
+			} `json:"baseMetricV3"`
+			V2 struct {
+				Severity string `json:"severity"`
+				CVSS     struct {
+					BaseScore float64 `json:"baseScore"`
+				} `json:"cvssV2"`
+			} `json:"baseMetricV2"`
+		} `json:"impact"`
+	} `json:"CVE_Items"`
+
+	// NVD API 2.0 responses
+	Vulnerabilities []struct {
+		CVE struct {
+			ID      string `json:"id"`
+			Metrics struct {
+				V40 []metric `json:"cvssMetricV40"`
+				V31 []metric `json:"cvssMetricV31"`
+				V30 []metric `json:"cvssMetricV30"`
+				V2  []metric `json:"cvssMetricV2"`
+			} `json:"metrics"`
+			Configurations []struct {
+				Operator string `json:"operator"`
+				Negate   bool   `json:"negate"`
+				Nodes    []node `json:"nodes"`
+			} `json:"configurations"`
+		} `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+// Parse decodes an NVD feed.
+func Parse(data []byte) (*DB, error) {
+	var f feed
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("Parsing vulnerability database: %w", err)
+	}
+	if f.Items == nil && f.Vulnerabilities == nil {
+		return nil, fmt.Errorf("Parsing vulnerability database: neither CVE_Items nor vulnerabilities found")
+	}
+
+	db := &DB{rules: map[string][]rule{}}
+	for _, it := range f.Items {
+		c := CVE{ID: it.CVE.Meta.ID, Severity: it.Impact.V3.CVSS.BaseSeverity, Score: it.Impact.V3.CVSS.BaseScore}
+		if c.Severity == "" {
+			c.Severity, c.Score = it.Impact.V2.Severity, it.Impact.V2.CVSS.BaseScore
+		}
+		db.add(c, it.Configurations.Nodes, false)
+	}
+	for _, v := range f.Vulnerabilities {
+		c := CVE{ID: v.CVE.ID}
+		m := v.CVE.Metrics
+		for _, list := range [][]metric{m.V40, m.V31, m.V30, m.V2} {
+			if len(list) > 0 {
+				c.Score, c.Severity = list[0].CVSSData.BaseScore, list[0].CVSSData.BaseSeverity
+				if c.Severity == "" {
+					c.Severity = list[0].BaseSeverity
+				}
+				break
+			}
+		}
+		for _, conf := range v.CVE.Configurations {
+			if !conf.Negate {
+				db.add(c, conf.Nodes, strings.EqualFold(conf.Operator, "AND"))
+			}
+		}
+	}
+	return db, nil
+}
+
+// add records the vulnerable CPE matches of a configuration. Negated nodes
+// exclude configurations rather than describe vulnerable ones and are
+// skipped; matches below an AND node are recorded as conditional.
+func (db *DB) add(c CVE, nodes []node, and bool) {
+	c.Severity = strings.ToLower(c.Severity)
+	idx := -1
+	var walk func([]node, bool)
+	walk = func(nodes []node, and bool) {
+		for _, n := range nodes {
+			if n.Negate {
+				continue
+			}
+			and := and || strings.EqualFold(n.Operator, "AND")
+			for _, m := range append(n.CPEMatch, n.CPEMatch2...) {
+				uri := m.Cpe23URI
+				if uri == "" {
+					uri = m.Criteria
+				}
+				f := strings.Split(uri, ":")
+				if !m.Vulnerable || len(f) < 6 {
+					continue
+				}
+				update := "*"
+				if len(f) > 6 {
+					update = f[6]
+				}
+				if idx < 0 {
+					idx = len(db.cves)
+					db.cves = append(db.cves, c)
+				}
+				db.rules[f[4]] = append(db.rules[f[4]], rule{
+					cve: idx, vendor: f[3], version: f[5], update: update,
+					startIncl: m.VersionStartIncluding, startExcl: m.VersionStartExcluding,
+					endIncl: m.VersionEndIncluding, endExcl: m.VersionEndExcluding,
+					conditional: and,
+				})
+			}
+			walk(n.Children, and)
+		}
+	}
+	walk(nodes, and)
+}
+
+// Len returns the number of CVEs with at least one vulnerable configuration.
+func (db *DB) Len() int {
+	return len(db.cves)
+}
+
+// Lookup returns the CVEs affecting the given product version, the high
+// confidence ones first, then the most severe.
+func (db *DB) Lookup(product, version string) []CVE {
+	name := strings.ToLower(strings.TrimSpace(product))
+	targets := aliases[name]
+	if targets == nil {
+		targets = []string{":" + strings.NewReplacer(" ", "_", "-", "_").Replace(name)}
+	}
+
+	// A CVE matched by several rules keeps its most confident match.
+	found := map[int]string{}
+	for _, t := range targets {
+		vendor, prod, _ := strings.Cut(t, ":")
+		for _, r := range db.rules[prod] {
+			if (vendor != "" && r.vendor != vendor) || !r.matches(version) {
+				continue
+			}
+			if conf := r.confidence(); found[r.cve] != "high" {
+				found[r.cve] = conf
+			}
+		}
+	}
+	out := make([]CVE, 0, len(found))
+	for idx, conf := range found {
+		c := db.cves[idx]
+		c.Confidence = conf
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Confidence != out[j].Confidence {
+			return out[i].Confidence == "high"
+		}
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// anyValue reports whether a CPE field is a wildcard (*) or not applicable
+// (-).
+func anyValue(f string) bool {
+	return f == "*" || f == "-" || f == ""
+}
+
+// allVersions reports whether r lists every version of the product: a
+// wildcard version without a range.
+func (r rule) allVersions() bool {
+	return anyValue(r.version) && r.startIncl == "" && r.startExcl == "" && r.endIncl == "" && r.endExcl == ""
+}
+
+func (r rule) confidence() string {
+	if r.conditional || r.allVersions() {
+		return "low"
+	}
+	return "high"
+}
+
+func (r rule) matches(v string) bool {
+	if !anyValue(r.version) {
+		// The update is a pre-release or patch level of the version
+		// (1.18.0:beta1), which the server announces as 1.18.0-beta1 or
+		// 1.18.0beta1. "-" means the plain release.
+		want := r.version
+		if !anyValue(r.update) {
+			want += "-" + r.update
+		}
+		return Compare(v, want) == 0
+	}
+	if !anyValue(r.update) {
+		// An update of every version, such as *:beta1, cannot be told apart
+		// from the announced version.
+		return false
+	}
+	switch {
+	case r.startIncl != "" && Compare(v, r.startIncl) < 0,
+		r.startExcl != "" && Compare(v, r.startExcl) <= 0,
+		r.endIncl != "" && Compare(v, r.endIncl) > 0,
+		r.endExcl != "" && Compare(v, r.endExcl) >= 0:
+		return false
+	}
+	return true
+}
+
+// Compare orders two version strings segment by segment: runs of digits
+// compare numerically, runs of letters lexically, and a version with extra
+// segments sorts after its prefix (1.1.1f > 1.1.1). Pre-releases, letters
+// after a '-' or '~' or one of alpha, beta, rc, pre and dev, sort before
+// the release (1.0.0-rc1 < 1.0.0).
+func Compare(a, b string) int {
+	sa, sb := segments(a), segments(b)
+	for i := 0; i < len(sa) && i < len(sb); i++ {
+		x, y := sa[i], sb[i]
+		xd, yd := x.text[0] >= '0' && x.text[0] <= '9', y.text[0] >= '0' && y.text[0] <= '9'
+		switch {
+		case xd && yd:
+			x, y := strings.TrimLeft(x.text, "0"), strings.TrimLeft(y.text, "0")
+			if len(x) != len(y) {
+				return sign(len(x) - len(y))
+			}
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		case xd != yd:
+			// A numeric segment is newer than a letter one (1.0.1 > 1.0.beta).
+			if xd {
+				return 1
+			}
+			return -1
+		case x.pre != y.pre:
+			// A patch letter is newer than a pre-release (1.0a > 1.0-rc).
+			if x.pre {
+				return -1
+			}
+			return 1
+		default:
+			if c := strings.Compare(x.text, y.text); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(sa) > len(sb) && sa[len(sb)].pre:
+		return -1
+	case len(sb) > len(sa) && sb[len(sa)].pre:
+		return 1
+	}
+	return sign(len(sa) - len(sb))
+}
+
+// preReleases are the letter segments that mark a pre-release even without
+// a '-' before them (1.18.0beta1).
+var preReleases = map[string]bool{"alpha": true, "beta": true, "rc": true, "pre": true, "dev": true}
+
+// segment is a run of digits or letters of a version. pre is set on letter
+// runs that start a pre-release.
+type segment struct {
+	text string
+	pre  bool
+}
+
+func segments(v string) []segment {
+	var out []segment
+	v = strings.ToLower(v)
+	for i := 0; i < len(v); {
+		c := v[i]
+		isDigit := c >= '0' && c <= '9'
+		isAlpha := c >= 'a' && c <= 'z'
+		if !isDigit && !isAlpha {
+			i++
+			continue
+		}
+		j := i
+		for j < len(v) && ((isDigit && v[j] >= '0' && v[j] <= '9') || (isAlpha && v[j] >= 'a' && v[j] <= 'z')) {
+			j++
+		}
+		seg := segment{text: v[i:j]}
+		if isAlpha {
+			seg.pre = preReleases[seg.text] || (i > 0 && (v[i-1] == '-' || v[i-1] == '~'))
+		}
+		out = append(out, seg)
+		i = j
+	}
+	return out
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,151 @@
+package vulndb
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "1.0.1", -1},
+		{"1.10.0", "1.9.9", 1},
+		{"1.02", "1.2", 0},
+		{"1.0", "1.0.0", -1},
+		{"1.1.1f", "1.1.1", 1},
+		{"1.1.1f", "1.1.1g", -1},
+		{"1.0.1", "1.0.beta", 1},
+		{"1.0.0-rc1", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc1", 1},
+		{"1.0.0-rc1", "1.0.0-rc2", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-beta1", "1.0.0-rc1", -1},
+		{"1.18.0beta1", "1.18.0", -1},
+		{"1.18.0beta1", "1.18.0-beta1", 0},
+		{"1.0.0-rc1", "1.0.0a", -1},
+		{"2.0.0-rc1", "1.9.9", 1},
+		{"7.4p1", "7.4", 1},
+		{"V1.2", "v1.2", 0},
+		{"", "", 0},
+		{"", "1", -1},
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    rule
+		version string
+		want    bool
+	}{
+		{"exact", rule{version: "1.18.0", update: "*"}, "1.18.0", true},
+		{"exact other", rule{version: "1.18.0", update: "*"}, "1.18.1", false},
+		{"exact release", rule{version: "1.18.0", update: "-"}, "1.18.0", true},
+		{"release is not a beta", rule{version: "1.18.0", update: "beta1"}, "1.18.0", false},
+		{"beta", rule{version: "1.18.0", update: "beta1"}, "1.18.0-beta1", true},
+		{"beta without dash", rule{version: "1.18.0", update: "beta1"}, "1.18.0beta1", true},
+		{"beta is not the release", rule{version: "1.18.0", update: "-"}, "1.18.0-beta1", false},
+		{"start including, at", rule{version: "*", update: "*", startIncl: "1.2.0"}, "1.2.0", true},
+		{"start including, below", rule{version: "*", update: "*", startIncl: "1.2.0"}, "1.1.9", false},
+		{"start excluding, at", rule{version: "*", update: "*", startExcl: "1.2.0"}, "1.2.0", false},
+		{"start excluding, above", rule{version: "*", update: "*", startExcl: "1.2.0"}, "1.2.1", true},
+		{"end including, at", rule{version: "*", update: "*", endIncl: "1.4.0"}, "1.4.0", true},
+		{"end including, above", rule{version: "*", update: "*", endIncl: "1.4.0"}, "1.4.1", false},
+		{"end excluding, at", rule{version: "*", update: "*", endExcl: "1.4.0"}, "1.4.0", false},
+		{"end excluding, below", rule{version: "*", update: "*", endExcl: "1.4.0"}, "1.3.99", true},
+		{"end excluding, pre-release", rule{version: "*", update: "*", endExcl: "1.4.0"}, "1.4.0-rc1", true},
+		{"range, inside", rule{version: "*", update: "*", startIncl: "1.2.0", endExcl: "1.4.0"}, "1.3.0", true},
+		{"range, outside", rule{version: "*", update: "*", startIncl: "1.2.0", endExcl: "1.4.0"}, "1.0.0", false},
+		{"all versions", rule{version: "*", update: "*"}, "0.1", true},
+		{"all versions of an update", rule{version: "*", update: "beta1"}, "1.0", false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.matches(tt.version); got != tt.want {
+			t.Errorf("%s: matches(%q) = %v, want %v", tt.name, tt.version, got, tt.want)
+		}
+	}
+}
+
+const feed20 = `{"vulnerabilities": [
+  {"cve": {"id": "CVE-0000-0001",
+    "metrics": {"cvssMetricV31": [{"cvssData": {"baseScore": 9.8, "baseSeverity": "CRITICAL"}}]},
+    "configurations": [{"nodes": [{"operator": "OR", "cpeMatch": [
+      {"vulnerable": true, "criteria": "cpe:2.3:a:f5:nginx:*:*:*:*:*:*:*:*", "versionStartIncluding": "1.18.0", "versionEndExcluding": "1.20.1"}]}]}]}},
+  {"cve": {"id": "CVE-0000-0002",
+    "metrics": {"cvssMetricV31": [{"cvssData": {"baseScore": 5.3, "baseSeverity": "MEDIUM"}}]},
+    "configurations": [{"nodes": [{"operator": "OR", "cpeMatch": [
+      {"vulnerable": true, "criteria": "cpe:2.3:a:f5:nginx:*:*:*:*:*:*:*:*"}]}]}]}},
+  {"cve": {"id": "CVE-0000-0003",
+    "metrics": {"cvssMetricV31": [{"cvssData": {"baseScore": 7.5, "baseSeverity": "HIGH"}}]},
+    "configurations": [{"operator": "AND", "nodes": [
+      {"operator": "OR", "cpeMatch": [{"vulnerable": true, "criteria": "cpe:2.3:a:f5:nginx:1.18.0:*:*:*:*:*:*:*"}]},
+      {"operator": "OR", "cpeMatch": [{"vulnerable": false, "criteria": "cpe:2.3:o:vendor:appliance:-:*:*:*:*:*:*:*"}]}]}]}},
+  {"cve": {"id": "CVE-0000-0004",
+    "metrics": {"cvssMetricV31": [{"cvssData": {"baseScore": 8.1, "baseSeverity": "HIGH"}}]},
+    "configurations": [{"nodes": [{"operator": "OR", "cpeMatch": [
+      {"vulnerable": true, "criteria": "cpe:2.3:a:f5:nginx:1.18.0:beta1:*:*:*:*:*:*"}]}]}]}},
+  {"cve": {"id": "CVE-0000-0005",
+    "metrics": {"cvssMetricV31": [{"cvssData": {"baseScore": 6.1, "baseSeverity": "MEDIUM"}}]},
+    "configurations": [{"negate": true, "nodes": [{"operator": "OR", "cpeMatch": [
+      {"vulnerable": true, "criteria": "cpe:2.3:a:f5:nginx:1.18.0:*:*:*:*:*:*:*"}]}]},
+      {"nodes": [{"operator": "OR", "negate": true, "cpeMatch": [
+      {"vulnerable": true, "criteria": "cpe:2.3:a:f5:nginx:1.18.0:*:*:*:*:*:*:*"}]}]}]}}
+]}`
+
+const feed11 = `{"CVE_Items": [
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0000-0011"}},
+   "impact": {"baseMetricV3": {"cvssV3": {"baseScore": 7.5, "baseSeverity": "HIGH"}}},
+   "configurations": {"nodes": [{"operator": "AND", "children": [
+     {"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:apache:http_server:2.4.49:*:*:*:*:*:*:*"}]},
+     {"operator": "OR", "cpe_match": [{"vulnerable": false, "cpe23Uri": "cpe:2.3:o:vendor:os:-:*:*:*:*:*:*:*"}]}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0000-0012"}},
+   "impact": {"baseMetricV2": {"severity": "MEDIUM", "cvssV2": {"baseScore": 5.0}}},
+   "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+     {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:apache:http_server:*:*:*:*:*:*:*:*", "versionEndIncluding": "2.4.49"}]}]}}
+]}`
+
+func TestLookup(t *testing.T) {
+	type hit struct {
+		id, confidence string
+	}
+	tests := []struct {
+		feed             string
+		product, version string
+		want             []hit
+	}{
+		{feed20, "nginx", "1.18.0", []hit{{"CVE-0000-0001", "high"}, {"CVE-0000-0003", "low"}, {"CVE-0000-0002", "low"}}},
+		{feed20, "nginx", "1.18.0-beta1", []hit{{"CVE-0000-0004", "high"}, {"CVE-0000-0002", "low"}}},
+		{feed20, "nginx", "1.20.1", []hit{{"CVE-0000-0002", "low"}}},
+		{feed11, "Apache", "2.4.49", []hit{{"CVE-0000-0012", "high"}, {"CVE-0000-0011", "low"}}},
+		{feed11, "Apache", "2.4.50", nil},
+	}
+	for _, tt := range tests {
+		db, err := Parse([]byte(tt.feed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := db.Lookup(tt.product, tt.version)
+		if len(got) != len(tt.want) {
+			t.Errorf("Lookup(%q, %q) = %v, want %v", tt.product, tt.version, got, tt.want)
+			continue
+		}
+		for i, c := range got {
+			if c.ID != tt.want[i].id || c.Confidence != tt.want[i].confidence {
+				t.Errorf("Lookup(%q, %q)[%d] = %s (%s), want %s (%s)", tt.product, tt.version, i, c.ID, c.Confidence, tt.want[i].id, tt.want[i].confidence)
+			}
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, data := range []string{``, `{`, `{}`, `[]`} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", data)
+		}
+	}
+}